@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+)
+
+// atomicValue reports whether typ is one of the types in sync/atomic, which
+// must be accessed through their methods, value is the type of values they hold.
+func atomicValue(typ types.Type) (value types.Type, ok bool) {
+	named, isNamed := typ.(*types.Named)
+	if !isNamed || named.Obj().Pkg() == nil || named.Obj().Pkg().Path() != "sync/atomic" {
+		return nil, false
+	}
+	if ok, _ := loadStore(typ); !ok {
+		return nil, false
+	}
+	load, _, _ := types.LookupFieldOrMethod(typ, true, named.Obj().Pkg(), "Load")
+	return load.Type().(*types.Signature).Results().At(0).Type(), true
+}
+
+func genAtomicFieldGetter(w io.Writer, receiver string, method string, field string, typ string) {
+	fmt.Fprintf(w, "func (instance *%s) %s() %s { return instance.%s.Load() }\n",
+		receiver, method, typ, field)
+}
+
+func genAtomicFieldSetter(w io.Writer, receiver string, method string, field string, typ string, track *trackCtx) {
+	if track == nil {
+		fmt.Fprintf(w, "func (instance *%s) %s(value %s) { instance.%s.Store(value) }\n",
+			receiver, method, typ, field)
+		return
+	}
+	fmt.Fprintf(w, "func (instance *%s) %s(value %s) {\n", receiver, method, typ)
+	fmt.Fprintf(w, "instance.%s.Store(value)\n", field)
+	genAtomicFieldMark(w, field, track)
+	fmt.Fprintf(w, "}\n")
+}
+
+func genAtomicFieldSwap(w io.Writer, receiver string, method string, field string, typ string, track *trackCtx) {
+	if track == nil {
+		fmt.Fprintf(w, "func (instance *%s) %s(value %s) (old %s) { return instance.%s.Swap(value) }\n",
+			receiver, method, typ, typ, field)
+		return
+	}
+	fmt.Fprintf(w, "func (instance *%s) %s(value %s) (old %s) {\n", receiver, method, typ, typ)
+	fmt.Fprintf(w, "old = instance.%s.Swap(value)\n", field)
+	genAtomicFieldMark(w, field, track)
+	fmt.Fprintf(w, "return old\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+func genAtomicFieldCompareAndSwap(w io.Writer, receiver string, method string, field string, typ string, track *trackCtx) {
+	if track == nil {
+		fmt.Fprintf(w, "func (instance *%s) %s(old, value %s) (swapped bool) { return instance.%s.CompareAndSwap(old, value) }\n",
+			receiver, method, typ, field)
+		return
+	}
+	fmt.Fprintf(w, "func (instance *%s) %s(old, value %s) (swapped bool) {\n", receiver, method, typ)
+	fmt.Fprintf(w, "if swapped = instance.%s.CompareAndSwap(old, value); swapped {\n", field)
+	genAtomicFieldMark(w, field, track)
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "return swapped\n")
+	fmt.Fprintf(w, "}\n")
+}
+
+// genAtomicFieldMark records the change of an atomic field, the bitmask is not
+// atomic, so it is modified holding the lock of visc:all if there is one.
+func genAtomicFieldMark(w io.Writer, field string, track *trackCtx) {
+	if track.Lock != nil {
+		fmt.Fprintf(w, "instance.%s.Lock()\n", track.Lock.Field)
+		fmt.Fprintf(w, "%s\n", track.mark(field))
+		fmt.Fprintf(w, "instance.%s.Unlock()\n", track.Lock.Field)
+	} else {
+		fmt.Fprintf(w, "%s\n", track.mark(field))
+	}
+}
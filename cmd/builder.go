@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genBuilder generates a fluent builder for t if it has the
+// "visc:builder(name=TBuilder)" directive, fields tagged with
+// `builder:"required"` must be set before Build is called, and `builder:"-"`
+// excludes a field from the builder. The builder fills a *T allocated by its
+// constructor, so locks (and the bitmask of "visc:track") are never copied or
+// set, and sync/atomic fields are set through Store. Build hands the *T over
+// and resets the builder to a new *T.
+func (g *Generator) genBuilder(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "builder")
+	if !ok {
+		return
+	}
+	name, found := directive.Lookup("name")
+	if !found || name == "" {
+		name = t.Spec.Name.String() + "Builder"
+	}
+	cx := make([]*constructCtx, 0, len(t.Fields))
+	required := 0
+	for _, field := range t.Fields {
+		if field.Embedded || bookkeeping(t, field) {
+			continue
+		}
+		value, isAtomic := atomicValue(field.Type)
+		if !isAtomic && containsLock(field.Type) {
+			continue
+		}
+		ctx := &constructCtx{
+			Field: field.Name,
+			Set:   toCamel(field.Name),
+		}
+		if builderTag, ok := structTag(field).Lookup("builder"); ok {
+			builderParts := strings.Split(builderTag, ",")
+			if strings.TrimSpace(builderParts[0]) == "-" {
+				continue
+			}
+			for i, part := range builderParts {
+				switch part = strings.TrimSpace(part); {
+				case part == "required":
+					ctx.Required = true
+				case i == 0 && part != "" && part != "*":
+					ctx.Set = part
+				}
+			}
+		}
+		if ctx.Required {
+			required++
+		}
+		if isAtomic {
+			ctx.Type, ctx.Atomic = g.typeString(value), true
+		} else {
+			ctx.Type = g.toString(field.Ast.Type)
+		}
+		cx = append(cx, ctx)
+	}
+	var (
+		receiver = t.String()
+		params   = g.typeParams(t)
+		builder  = name + typeArgs(t)
+	)
+	fmt.Fprintf(&g.out, "\n\ntype %s%s struct {\ninstance *%s\n", name, params, receiver)
+	if required > 0 {
+		fmt.Fprintf(&g.out, "set [%d]bool\n", required)
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc New%s%s() *%s { return &%s{instance: new(%s)} }\n", name, params, builder, builder, receiver)
+	index := 0
+	for _, setter := range cx {
+		fmt.Fprintf(&g.out, "\nfunc (builder *%s) %s(value %s) *%s {\n", builder, setter.Set, setter.Type, builder)
+		if setter.Atomic {
+			fmt.Fprintf(&g.out, "builder.instance.%s.Store(value)\n", setter.Field)
+		} else {
+			fmt.Fprintf(&g.out, "builder.instance.%s = value\n", setter.Field)
+		}
+		if setter.Required {
+			fmt.Fprintf(&g.out, "builder.set[%d] = true\n", index)
+			index++
+		}
+		fmt.Fprintf(&g.out, "return builder\n")
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "\nfunc (builder *%s) Build() (*%s, error) {\n", builder, receiver)
+	if required > 0 {
+		fmt.Fprintf(&g.out, "var missing []string\n")
+		index = 0
+		for _, setter := range cx {
+			if setter.Required {
+				fmt.Fprintf(&g.out, "if !builder.set[%d] { missing = append(missing, %q) }\n", index, setter.Field)
+				index++
+			}
+		}
+		fmt.Fprintf(&g.out, "if len(missing) > 0 {\n")
+		fmt.Fprintf(&g.out, "return nil, %s.Errorf(\"%s: required fields are not set: %%s\", %s.Join(missing, \", \"))\n",
+			g.importPackage("fmt"), name, g.importPackage("strings"))
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	// the builder starts over with a new instance, so that the built instance
+	// is not changed by the builder afterwards
+	fmt.Fprintf(&g.out, "instance := builder.instance\n")
+	fmt.Fprintf(&g.out, "*builder = %s{instance: new(%s)}\n", builder, receiver)
+	fmt.Fprintf(&g.out, "return instance, nil\n")
+	fmt.Fprintf(&g.out, "}")
+}
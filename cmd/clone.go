@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genClone generates a Clone method performing a deep copy of t if it has the
+// "visc:clone" directive. The fields are read holding the lock of
+// "visc:all(lock=mu)", if any, and the clone gets a lock of its own.
+func (g *Generator) genClone(t *inspect.Type) {
+	if _, ok := lookupDirective(docComments(t), "clone"); !ok {
+		return
+	}
+	receiver := t.String()
+	c := g.newCopier()
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) Clone() *%s {\n", receiver, receiver)
+	fmt.Fprintf(&g.out, "if instance == nil {\nreturn nil\n}\n")
+	g.allLock(t).acquire(&g.out, true)
+	fmt.Fprintf(&g.out, "clone := new(%s)\n", receiver)
+	for _, field := range t.Fields {
+		if field.Name == "_" {
+			continue
+		}
+		c.copy(&g.out, "clone."+field.Name, "instance."+field.Name, g.fieldType(field))
+	}
+	fmt.Fprintf(&g.out, "return clone\n")
+	fmt.Fprintf(&g.out, "}")
+}
+
+// cloneable reports whether named has the "visc:clone" directive, its Clone
+// method may not exist yet.
+func (g *Generator) cloneable(named *types.Named) bool {
+	return g.hasDirective(named, "clone")
+}
+
+// copier generates statements which deep copy a value, every destination is
+// expected to hold the zero value of its type before being copied to.
+type copier struct {
+	g        *Generator
+	vars     int
+	visiting map[*types.Named]bool
+}
+
+func (g *Generator) newCopier() *copier {
+	return &copier{
+		g:        g,
+		visiting: make(map[*types.Named]bool),
+	}
+}
+
+func (c *copier) newVar(prefix string) string {
+	c.vars++
+	return fmt.Sprintf("%s%d", prefix, c.vars)
+}
+
+// cloneMethod reports whether typ has a Clone method returning a copy of it,
+// either as a value or as a pointer (ptrResult).
+func (c *copier) cloneMethod(typ types.Type) (ptrResult bool, ok bool) {
+	named, isNamed := typ.(*types.Named)
+	if !isNamed {
+		return false, false
+	}
+	if c.g.cloneable(named) {
+		return true, true
+	}
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, named.Obj().Pkg(), "Clone")
+	method, isFunc := obj.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	sig := method.Type().(*types.Signature)
+	if sig.Params().Len() != 0 || sig.Results().Len() != 1 {
+		return false, false
+	}
+	result := sig.Results().At(0).Type()
+	if types.Identical(result, typ) {
+		return false, true
+	}
+	if types.Identical(result, types.NewPointer(typ)) {
+		return true, true
+	}
+	return false, false
+}
+
+// loadStore reports whether the addressable typ provides Load and Store methods
+// like the types in sync/atomic, which is the only way to copy them, nilable
+// is true if the loaded value may be nil, which must not be stored.
+func loadStore(typ types.Type) (ok bool, nilable bool) {
+	var pkg *types.Package
+	if named, isNamed := typ.(*types.Named); isNamed {
+		pkg = named.Obj().Pkg()
+	}
+	load, _, _ := types.LookupFieldOrMethod(typ, true, pkg, "Load")
+	store, _, _ := types.LookupFieldOrMethod(typ, true, pkg, "Store")
+	loadFunc, isFunc := load.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	storeFunc, isFunc := store.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	loadSig := loadFunc.Type().(*types.Signature)
+	storeSig := storeFunc.Type().(*types.Signature)
+	if loadSig.Params().Len() != 0 || loadSig.Results().Len() != 1 ||
+		storeSig.Params().Len() != 1 || storeSig.Results().Len() != 0 {
+		return false, false
+	}
+	value := loadSig.Results().At(0).Type()
+	if !types.Identical(value, storeSig.Params().At(0).Type()) {
+		return false, false
+	}
+	return true, types.IsInterface(value)
+}
+
+// containsLock reports whether values of typ must not be copied, which is the
+// same rule go vet uses: the type, or one of its fields or elements, has
+// Lock and Unlock methods with pointer receivers.
+func containsLock(typ types.Type) bool {
+	if _, isPtr := typ.Underlying().(*types.Pointer); isPtr {
+		return false
+	}
+	if _, isParam := typ.(*types.TypeParam); isParam {
+		return false
+	}
+	var pkg *types.Package
+	if named, isNamed := typ.(*types.Named); isNamed {
+		pkg = named.Obj().Pkg()
+	}
+	lock, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), false, pkg, "Lock")
+	unlock, _, _ := types.LookupFieldOrMethod(types.NewPointer(typ), false, pkg, "Unlock")
+	if _, isFunc := lock.(*types.Func); isFunc {
+		if _, isFunc := unlock.(*types.Func); isFunc {
+			return true
+		}
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Array:
+		return containsLock(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if containsLock(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// accessible reports whether all fields of a struct may be accessed from the
+// package being generated.
+func (g *Generator) accessible(s *types.Struct) bool {
+	for i := 0; i < s.NumFields(); i++ {
+		if field := s.Field(i); !field.Exported() && field.Pkg().Path() != g.GetTypes().Path() {
+			return false
+		}
+	}
+	return true
+}
+
+// deep reports whether copying typ needs more than a plain assignment.
+func (c *copier) deep(typ types.Type) bool {
+	if _, isParam := typ.(*types.TypeParam); isParam {
+		return false
+	}
+	if _, ok := c.cloneMethod(typ); ok {
+		return true
+	}
+	if containsLock(typ) {
+		return true
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Pointer:
+		// pointers to types of other packages (e.g. *os.File) are shared
+		// unless they know how to clone themselves
+		if named, isNamed := u.Elem().(*types.Named); isNamed && named.Obj().Pkg() != nil &&
+			named.Obj().Pkg().Path() != c.g.GetTypes().Path() {
+			_, ok := c.cloneMethod(named)
+			return ok
+		}
+		return true
+	case *types.Slice, *types.Map:
+		return true
+	case *types.Array:
+		return c.deep(u.Elem())
+	case *types.Struct:
+		if !c.g.accessible(u) {
+			return false
+		}
+		if named, isNamed := typ.(*types.Named); isNamed {
+			if c.visiting[named.Origin()] {
+				return false
+			}
+			c.visiting[named.Origin()] = true
+			defer delete(c.visiting, named.Origin())
+		}
+		for i := 0; i < u.NumFields(); i++ {
+			if c.deep(u.Field(i).Type()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// copy generates statements deep copying src of type typ to dst, both of them
+// must be addressable expressions.
+func (c *copier) copy(w io.Writer, dst string, src string, typ types.Type) {
+	if !c.deep(typ) {
+		fmt.Fprintf(w, "%s = %s\n", dst, src)
+		return
+	}
+	if ptrResult, ok := c.cloneMethod(typ); ok {
+		if ptrResult {
+			fmt.Fprintf(w, "%s = *%s.Clone()\n", dst, src)
+		} else {
+			fmt.Fprintf(w, "%s = %s.Clone()\n", dst, src)
+		}
+		return
+	}
+	if containsLock(typ) {
+		if ok, nilable := loadStore(typ); ok {
+			if nilable {
+				value := c.newVar("v")
+				fmt.Fprintf(w, "if %s := %s.Load(); %s != nil {\n%s.Store(%s)\n}\n", value, src, value, dst, value)
+			} else {
+				fmt.Fprintf(w, "%s.Store(%s.Load())\n", dst, src)
+			}
+			return
+		}
+		// other locks are left as zero values since copying them is a bug,
+		// but the rest of the fields of a struct and the elements of an array
+		// containing them are copied
+		switch u := typ.Underlying().(type) {
+		case *types.Array:
+		case *types.Struct:
+			if !c.g.accessible(u) {
+				return
+			}
+		default:
+			return
+		}
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Pointer:
+		fmt.Fprintf(w, "if %s != nil {\n", src)
+		if ptrResult, ok := c.cloneMethod(u.Elem()); ok {
+			if ptrResult {
+				fmt.Fprintf(w, "%s = %s.Clone()\n", dst, src)
+			} else {
+				value := c.newVar("v")
+				fmt.Fprintf(w, "%s := %s.Clone()\n%s = &%s\n", value, src, dst, value)
+			}
+		} else {
+			fmt.Fprintf(w, "%s = new(%s)\n", dst, c.g.typeString(u.Elem()))
+			c.copy(w, "(*"+dst+")", "(*"+src+")", u.Elem())
+		}
+		fmt.Fprintf(w, "}\n")
+	case *types.Slice:
+		fmt.Fprintf(w, "if %s != nil {\n", src)
+		fmt.Fprintf(w, "%s = make(%s, len(%s))\n", dst, c.g.typeString(typ), src)
+		if c.deep(u.Elem()) {
+			index := c.newVar("i")
+			fmt.Fprintf(w, "for %s := range %s {\n", index, src)
+			c.copy(w, dst+"["+index+"]", src+"["+index+"]", u.Elem())
+			fmt.Fprintf(w, "}\n")
+		} else {
+			fmt.Fprintf(w, "copy(%s, %s)\n", dst, src)
+		}
+		fmt.Fprintf(w, "}\n")
+	case *types.Map:
+		key, value := c.newVar("k"), c.newVar("v")
+		fmt.Fprintf(w, "if %s != nil {\n", src)
+		fmt.Fprintf(w, "%s = make(%s, len(%s))\n", dst, c.g.typeString(typ), src)
+		fmt.Fprintf(w, "for %s, %s := range %s {\n", key, value, src)
+		if c.deep(u.Elem()) {
+			elem := c.newVar("c")
+			fmt.Fprintf(w, "var %s %s\n", elem, c.g.typeString(u.Elem()))
+			c.copy(w, elem, value, u.Elem())
+			fmt.Fprintf(w, "%s[%s] = %s\n", dst, key, elem)
+		} else {
+			fmt.Fprintf(w, "%s[%s] = %s\n", dst, key, value)
+		}
+		fmt.Fprintf(w, "}\n")
+		fmt.Fprintf(w, "}\n")
+	case *types.Array:
+		// the loop is left out if there is nothing to copy, e.g. for locks
+		var body strings.Builder
+		index := c.newVar("i")
+		c.copy(&body, dst+"["+index+"]", src+"["+index+"]", u.Elem())
+		if body.Len() > 0 {
+			fmt.Fprintf(w, "for %s := range %s {\n%s}\n", index, src, body.String())
+		}
+	case *types.Struct:
+		if named, isNamed := typ.(*types.Named); isNamed {
+			// recursive types are only copied deeply up to the first level
+			// of recursion unless they have a Clone method
+			if c.visiting[named.Origin()] {
+				fmt.Fprintf(w, "%s = %s\n", dst, src)
+				return
+			}
+			c.visiting[named.Origin()] = true
+			defer delete(c.visiting, named.Origin())
+		}
+		for i := 0; i < u.NumFields(); i++ {
+			if field := u.Field(i); field.Name() != "_" {
+				c.copy(w, dst+"."+field.Name(), src+"."+field.Name(), field.Type())
+			}
+		}
+	default:
+		fmt.Fprintf(w, "%s = %s\n", dst, src)
+	}
+}
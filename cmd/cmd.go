@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"go/ast"
 	"go/format"
+	"go/types"
 	"io"
 	"log"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"reflect"
 	"regexp"
@@ -42,6 +44,7 @@ var (
 	buildTags   string
 	output      string
 	targetTypes []string
+	check       bool
 
 	setter            bool
 	setPrefix         string
@@ -62,52 +65,183 @@ var Command = &cobra.Command{
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		var dir string
-		file := os.Getenv(EnvGoFile)
-		if file == "" {
-			dir, _ = os.Getwd()
-		} else {
-			dir = filepath.Dir(file)
+		files, patterns := splitArgs(args)
+		if len(patterns) == 0 {
+			var dir string
+			file := os.Getenv(EnvGoFile)
+			if file == "" {
+				dir, _ = os.Getwd()
+			} else {
+				dir = filepath.Dir(file)
+			}
+			code, _, err := render(dir, files, output)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			upToDate, err := emit(output, code)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if !upToDate {
+				log.Fatalf("%s is out of date, please run %s again", output, ProgramName)
+			}
+			return
 		}
 
-		pkg, err := inspect.Scan(dir, args, targetTypes)
+		if len(files) > 0 {
+			log.Fatalln("Files and package patterns can not be specified at the same time.")
+		}
+		if filepath.IsAbs(output) {
+			log.Fatalln("When package patterns are specified, output should be a path relative to each package directory.")
+		}
+		dirs, err := inspect.ListPackages(patterns)
 		if err != nil {
 			log.Fatalln(err)
 		}
-
-		g := &Generator{
-			Package:    pkg,
-			Generator:  GeneratorName,
-			Tag:        buildTags,
-			mustImport: make(map[*inspect.Import]struct{}),
+		// every package is rendered before any file is written, so that a
+		// failure in one package does not leave a partial set of files behind
+		type generated struct {
+			path string
+			code []byte
+		}
+		outputs := make([]generated, 0, len(dirs))
+		for _, dir := range dirs {
+			path := filepath.Join(dir, output)
+			code, empty, err := render(dir, nil, path)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			// packages without any visc directive or tag are skipped, so that
+			// patterns like "./..." do not leave empty files everywhere, but a
+			// file generated for them before is stale and removed
+			if !empty {
+				outputs = append(outputs, generated{path: path, code: code})
+			} else if stale, err := generatedBy(path); err != nil {
+				log.Fatalln(err)
+			} else if stale {
+				outputs = append(outputs, generated{path: path})
+			}
+		}
+		var stale []string
+		for _, file := range outputs {
+			upToDate, err := emit(file.path, file.code)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if !upToDate {
+				stale = append(stale, file.path)
+			}
 		}
-		g.preload()
+		if len(stale) > 0 {
+			log.Fatalf("%d generated files are out of date, please run %s again:\n\t%s",
+				len(stale), ProgramName, strings.Join(stale, "\n\t"))
+		}
+	},
+}
 
-		var code bytes.Buffer
-		if err = template.Must(
-			template.New(ProgramName).Parse(genTemplate),
-		).Execute(&code, g); err != nil {
-			log.Fatalln(err)
+// emit writes code to path, or in check mode, compares code with the content
+// of path and prints the differences, upToDate is false if they differ. A nil
+// code means that path should not exist and it is removed.
+func emit(path string, code []byte) (upToDate bool, err error) {
+	if !check {
+		if code == nil {
+			return true, os.Remove(path)
 		}
+		return true, os.WriteFile(path, code, 0644)
+	}
+	current, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	if bytes.Equal(current, code) {
+		return true, nil
+	}
+	fmt.Print(unifiedDiff(path, path+" (generated)", current, code))
+	return false, nil
+}
 
-		formatted, err := format.Source(code.Bytes())
-		if err != nil {
-			log.Fatalln(err)
+// generatedBy reports whether path is a file generated by visc, files written
+// by hand are never removed even if they are named as the output.
+func generatedBy(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
 		}
+		return false, err
+	}
+	header := []byte("// Code generated by " + GeneratorName + ", DO NOT EDIT.")
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		if bytes.Equal(bytes.TrimSpace(line), header) {
+			return true, nil
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("package ")) {
+			break
+		}
+	}
+	return false, nil
+}
 
-		if err = os.WriteFile(output, formatted, 0644); err != nil {
-			log.Fatalln(err)
+// splitArgs separates go files from package patterns in command-line arguments.
+func splitArgs(args []string) (files []string, patterns []string) {
+	for _, arg := range args {
+		if strings.HasSuffix(arg, ".go") {
+			files = append(files, arg)
+		} else {
+			patterns = append(patterns, arg)
 		}
+	}
+	return
+}
+
+// render generates the code for the package in dir, output is the path of the
+// generated file and empty reports whether there is no generated method at all.
+func render(dir string, files []string, output string) (code []byte, empty bool, err error) {
+	pkg, err := inspect.Scan(dir, files, targetTypes)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// type errors are positioned by the absolute paths of the scanned files
+	if output, err = filepath.Abs(output); err != nil {
+		return nil, false, err
+	}
 
-		fixed, err := goimport.Process(output, formatted, nil)
+	g := &Generator{
+		Package:    pkg,
+		Generator:  GeneratorName,
+		Tag:        buildTags,
+		mustImport: make(map[*inspect.Import]struct{}),
+		locals:     make(map[string]string),
+		paths:      make(map[string]string),
+		tracks:     make(map[*inspect.Type]*trackCtx),
+		options:    make(map[string]string),
+		output:     output,
+	}
+	g.preload()
+
+	var buf bytes.Buffer
+	if err = template.Must(
+		template.New(ProgramName).Parse(genTemplate),
+	).Execute(&buf, g); err != nil {
+		return nil, false, err
+	}
+
+	code, err = format.Source(buf.Bytes())
+	if err != nil {
+		return nil, false, err
+	}
+
+	// imports are resolved through type information, goimports is only
+	// needed when some of the types could not be resolved
+	if g.fixImports {
+		code, err = goimport.Process(output, code, nil)
 		if err != nil {
-			log.Fatalln(err)
+			return nil, false, err
 		}
+	}
 
-		if err = os.WriteFile(output, fixed, 0644); err != nil {
-			log.Fatalln(err)
-		}
-	},
+	return code, strings.TrimSpace(g.Code()) == "", nil
 }
 
 func init() {
@@ -120,6 +254,7 @@ func init() {
 	flags.StringVar(&buildTags, "buildtags", "", "tags attached to output file")
 	flags.StringVar(&output, "output", "", "output file")
 	flags.StringSliceVar(&targetTypes, "types", nil, "target type")
+	flags.BoolVar(&check, "check", false, "check whether generated files are up to date instead of writing them")
 	Command.MarkPersistentFlagRequired("output")
 
 	flags.BoolVar(&setter, "setter", false, "setter flag")
@@ -136,6 +271,12 @@ type Generator struct {
 	Generator  string
 	Tag        string
 	mustImport map[*inspect.Import]struct{}
+	locals     map[string]string           // package path to name in the generated file
+	paths      map[string]string           // name in the generated file to package path
+	tracks     map[*inspect.Type]*trackCtx // bitmasks of visc:track shared by directives
+	options    map[string]string           // option functions to the fields they set
+	output     string                      // absolute path of the generated file
+	fixImports bool
 	out        strings.Builder
 }
 
@@ -144,6 +285,12 @@ func (g *Generator) MustImport() []*inspect.Import {
 	for imported := range g.mustImport {
 		imports = append(imports, imported)
 	}
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].Path != imports[j].Path {
+			return imports[i].Path < imports[j].Path
+		}
+		return imports[i].Name < imports[j].Name
+	})
 	return imports
 }
 
@@ -157,6 +304,23 @@ func (g *Generator) preload() {
 	})
 	for _, target := range g.Targets {
 		g.genGetterSetter(target)
+		g.genOptions(target)
+		g.genBuilder(target)
+		g.genClone(target)
+		g.genFields(target)
+		g.genVisit(target)
+		g.genEqual(target)
+		g.genStringer(target)
+		g.genValidate(target)
+		g.genJSON(target)
+		g.genSQL(target)
+		g.genMap(target)
+		g.genPatch(target)
+		// generated last since directives above (e.g. visc:patch) may track
+		// changes of more fields
+		if track := g.tracks[target]; track != nil {
+			g.genTrack(target, track)
+		}
 	}
 }
 
@@ -165,11 +329,16 @@ var fnRe = regexp.MustCompile(`^(\w+?)\((.+?)\)$`)
 func (g *Generator) genGetterSetter(t *inspect.Type) {
 	fmt.Fprintf(&g.out, "\n\n")
 	var (
-		all          bool
-		allGetPrefix string
-		allSetPrefix string
-		allGetter    bool
-		allSetter    bool
+		all           bool
+		allGetPrefix  string
+		allSetPrefix  string
+		allGetter     bool
+		allSetter     bool
+		allWither     bool
+		allCopy       bool
+		allLock       *lockCtx
+		track         *trackCtx
+		allWithPrefix = "With"
 	)
 	var (
 		construct       bool
@@ -177,13 +346,7 @@ func (g *Generator) genGetterSetter(t *inspect.Type) {
 		constructPrefix string
 	)
 	if t.Decl.Doc != nil || t.Spec.Doc != nil {
-		list := make([]*ast.Comment, 8)
-		if t.Decl.Doc != nil {
-			list = append(list, t.Decl.Doc.List...)
-		}
-		if t.Spec.Doc != nil {
-			list = append(list, t.Spec.Doc.List...)
-		}
+		list := docComments(t)
 		drtAll := getDirective(list, "all")
 		if all = drtAll != ""; all {
 			allGetPrefix, _ = drtAll.Lookup("getPrefix")
@@ -196,6 +359,23 @@ func (g *Generator) genGetterSetter(t *inspect.Type) {
 			if b, err := strconv.ParseBool(allSetterOpt); found && err == nil {
 				allSetter = b
 			}
+			allCopyOpt, found := drtAll.Lookup("copy")
+			if b, err := strconv.ParseBool(allCopyOpt); found && err == nil {
+				allCopy = b
+			}
+			allLock = g.allLock(t)
+			allWitherOpt, found := drtAll.Lookup("withers")
+			if b, err := strconv.ParseBool(allWitherOpt); found && err == nil {
+				allWither = b
+			}
+			if withPrefix, found := drtAll.Lookup("withPrefix"); found {
+				allWithPrefix = withPrefix
+			}
+		}
+		if field, found := trackField(t); found {
+			track = g.lookupTrack(t, field)
+			track.Lock = allLock
+			g.tracks[t] = track
 		}
 		drtConstruct := getDirective(list, "construct")
 		if construct = drtConstruct != ""; construct {
@@ -232,80 +412,358 @@ func (g *Generator) genGetterSetter(t *inspect.Type) {
 		constructPrefix = constructorPrefix
 	}
 	receiver := t.String()
-	structType := t.Spec.Type.(*ast.StructType)
-	cx := make([]*constructCtx, 0, len(structType.Fields.List))
-	for _, field := range structType.Fields.List {
-		for _, name := range field.Names {
-			var tag reflect.StructTag
-			if lit := field.Tag; lit != nil {
-				tag = reflect.StructTag(lit.Value[1 : len(lit.Value)-1])
-			}
-			getter, hasGetter, isRef, setter, hasSetter := inspectField(
-				name.String(),
-				tag,
-			)
-			if getterTag := tag.Get("getter"); getterTag != "-" && all && allGetter && !hasGetter {
-				getter, hasGetter, isRef = allGetPrefix+toCamel(name.String()), true, false
-			}
-			if setterTag := tag.Get("setter"); setterTag != "-" && all && allSetter && !hasSetter {
-				setter, hasSetter = allSetPrefix+toCamel(name.String()), true
-			}
-			var typ string
-			if hasGetter || hasSetter {
-				typ = g.toString(field.Type)
-			}
-			if hasGetter {
-				genFieldGetter(&g.out, receiver, getter, name.String(), typ, isRef)
-			}
-			if constructFunc, ok := tag.Lookup("construct"); ok {
-				if match := fnRe.FindStringSubmatch(constructFunc); match != nil && len(match) > 2 {
-					cx = append(cx, &constructCtx{
-						Field: name.String(),
-						Type:  match[2],
-						Set:   match[1],
-					})
-				}
-			} else if hasSetter {
-				genFieldSetter(&g.out, receiver, setter, name.String(), typ)
+	cx := make([]*constructCtx, 0, len(t.Fields))
+	methods := make(map[string]bool)
+	accessors := new(accessorCtx)
+	promotes := make([]*promoteCtx, 0)
+	// withers have value receivers, which must not copy a lock held by t
+	holdsLock := t.Named != nil && containsLock(t.Named)
+	for index, field := range t.Fields {
+		name := field.Name
+		tag := structTag(field)
+		if allLock != nil && name == allLock.Field {
+			// the lock itself can neither be copied nor replaced
+			continue
+		}
+		if track != nil && name == track.Field {
+			continue
+		}
+		getLock, setLock := allLock, allLock
+		if lock, found := lookupTagOption(tag, "getter", "lock"); found {
+			getLock = g.lookupLock(t, lock, field.Ast.Tag.Pos())
+		}
+		if lock, found := lookupTagOption(tag, "setter", "lock"); found {
+			setLock = g.lookupLock(t, lock, field.Ast.Tag.Pos())
+			if track != nil && (allLock == nil || setLock.Field != allLock.Field) {
+				// the bitmask of visc:track is guarded by the lock of visc:all
+				log.Fatalf("%s: field %s is set holding lock %s, but changes of visc:track are not guarded by it, use visc:all(lock=%s) instead",
+					g.GetFset().Position(field.Ast.Tag.Pos()), name, lock, lock)
+			}
+		}
+		getter, hasGetter, isRef, isCopy, setter, hasSetter := inspectField(
+			name,
+			tag,
+		)
+		// values of locks must not be copied, visc:all leaves them alone
+		_, isAtomic := atomicValue(field.Type)
+		fieldAll := all && (isAtomic || !containsLock(field.Type))
+		if getterTag := tag.Get("getter"); getterTag != "-" && fieldAll && allGetter && !hasGetter {
+			getter, hasGetter, isRef, isCopy = allGetPrefix+toCamel(name), true, false, allCopy
+		}
+		if setterTag := tag.Get("setter"); setterTag != "-" && fieldAll && allSetter && !hasSetter {
+			setter, hasSetter = allSetPrefix+toCamel(name), true
+		}
+		if field.Embedded {
+			// a method can not have the same name as a field, which is the
+			// case for the getter of an embedded field of an exported type
+			if getter == name {
+				getter = "Get" + name
+			}
+			promote := &promoteCtx{
+				Index:  index,
+				Getter: hasTagOption(tag, "getter", "promote"),
+				Setter: hasTagOption(tag, "setter", "promote"),
+			}
+			if promote.Getter || promote.Setter {
+				promotes = append(promotes, promote)
+			}
+		}
+		methods[getter], methods[setter] = hasGetter, hasSetter
+		wither, hasWither := selectField(tag, "wither", name, allWithPrefix, fieldAll && allWither)
+		// fields of sync/atomic types are accessed through the values they hold,
+		// and withers would copy them
+		value, _ := atomicValue(field.Type)
+		hasWither = hasWither && !isAtomic
+		if hasWither && holdsLock {
+			if _, tagged := tag.Lookup("wither"); tagged {
+				log.Fatalf("%s: wither of field %s would copy the lock held by %s, use a setter instead",
+					g.GetFset().Position(field.Ast.Tag.Pos()), name, t.Spec.Name.String())
+			}
+			hasWither = false
+		}
+		var typ string
+		if isAtomic && (hasGetter || hasSetter) {
+			typ = g.typeString(value)
+		} else if hasGetter || hasSetter || hasWither {
+			typ = g.toString(field.Ast.Type)
+		}
+		if hasGetter {
+			if isAtomic && isRef {
+				accessors.getter(getter, refType(isRef)+g.toString(field.Ast.Type))
+			} else {
+				accessors.getter(getter, refType(isRef)+typ)
+			}
+			if isAtomic && !isRef {
+				genAtomicFieldGetter(&g.out, receiver, getter, name, typ)
+			} else if isAtomic {
+				genFieldGetter(&g.out, receiver, getter, name, g.toString(field.Ast.Type), isRef)
+			} else if isCopy && !isRef && g.newCopier().deep(g.fieldType(field)) {
+				g.genFieldCopyGetter(receiver, getter, name, typ, g.fieldType(field), getLock)
+			} else if getLock != nil {
+				genLockedFieldGetter(&g.out, receiver, getter, name, typ, isRef, getLock)
+			} else {
+				genFieldGetter(&g.out, receiver, getter, name, typ, isRef)
+			}
+		}
+		if hasWither {
+			genFieldWither(&g.out, receiver, wither, name, typ)
+		}
+		for _, proxy := range parseProxies(tag, "getter") {
+			g.genProxyGetter(receiver, field, proxy, methods, accessors, getLock)
+		}
+		for _, proxy := range parseProxies(tag, "setter") {
+			g.genProxySetter(receiver, field, proxy, methods, accessors, setLock, track)
+		}
+		if collection, ok := selectField(tag, "collection", name, "", false); ok {
+			item, _ := lookupTagOption(tag, "collection", "item")
+			g.genCollection(receiver, collection, item, field, getLock, setLock, track)
+		}
+		if constructFunc, ok := tag.Lookup("construct"); ok {
+			if match := fnRe.FindStringSubmatch(constructFunc); match != nil && len(match) > 2 {
 				cx = append(cx, &constructCtx{
-					Field: name.String(),
-					Type:  typ,
-					Set:   setter,
+					Field: name,
+					Type:  g.rawType(match[2]),
+					Set:   match[1],
 				})
 			}
+		} else if hasSetter {
+			accessors.setter(setter, typ)
+			if isAtomic {
+				genAtomicFieldSetter(&g.out, receiver, setter, name, typ, track)
+				if hasTagOption(tag, "setter", "swap") {
+					genAtomicFieldSwap(&g.out, receiver, "Swap"+toCamel(name), name, typ, track)
+				}
+				if hasTagOption(tag, "setter", "cas") {
+					genAtomicFieldCompareAndSwap(&g.out, receiver, "CompareAndSwap"+toCamel(name), name, typ, track)
+				}
+			} else if setLock != nil {
+				genLockedFieldSetter(&g.out, receiver, setter, name, typ, setLock, track.mark(name))
+			} else {
+				genFieldSetter(&g.out, receiver, setter, name, typ, track.mark(name))
+			}
+			cx = append(cx, &constructCtx{
+				Field: name,
+				Type:  typ,
+				Set:   setter,
+			})
+		}
+	}
+	if len(promotes) > 0 {
+		if allSetPrefix == "" {
+			allSetPrefix = "Set"
 		}
+		g.genPromoted(t, promotes, allGetPrefix, allSetPrefix, methods, accessors, allLock, track)
 	}
 	if construct {
 		g.genConstruct(receiver, constructName, constructPrefix, cx)
 	}
+	g.genInterface(t, accessors)
+}
+
+// hasTagOption reports whether option is one of the options following the
+// name in a getter/setter-style tag, e.g. `getter:"*,ref"` has the option ref.
+func hasTagOption(tag reflect.StructTag, key string, option string) bool {
+	_, found := lookupTagOption(tag, key, option)
+	return found
+}
+
+// lookupTagOption is like hasTagOption, it also returns the value of options
+// in the form of option=value, e.g. `getter:"*,lock=mu"`.
+func lookupTagOption(tag reflect.StructTag, key string, option string) (value string, found bool) {
+	content, ok := tag.Lookup(key)
+	if !ok {
+		return "", false
+	}
+	for _, part := range strings.Split(content, ",")[1:] {
+		part = strings.TrimSpace(part)
+		if part == option {
+			return "", true
+		}
+		if strings.HasPrefix(part, option+"=") {
+			return strings.TrimSpace(part[len(option)+1:]), true
+		}
+	}
+	return "", false
 }
 
 func (g *Generator) toString(expr ast.Expr) string {
+	info := g.GetInfo()
+	// package names are replaced with the names used by the generated file,
+	// which may differ from the scanned file when two files import different
+	// packages of the same name
+	renamed := make(map[*ast.Ident]string)
+	ast.Inspect(expr, func(node ast.Node) bool {
+		switch x := node.(type) {
+		case *ast.SelectorExpr:
+			if ident, ok := x.X.(*ast.Ident); ok {
+				if pkgName, ok := info.Uses[ident].(*types.PkgName); ok {
+					pkg := pkgName.Imported()
+					if name := g.qualify(pkg.Path(), pkgName.Name(), pkg.Name()); name != ident.Name {
+						renamed[ident] = ident.Name
+						ident.Name = name
+					}
+				} else {
+					g.fixImports = true
+				}
+				return false
+			}
+		case *ast.Ident:
+			switch obj := info.Uses[x].(type) {
+			case *types.TypeName:
+				// a type from another package referenced without qualifier
+				// can only come from a dot import
+				if pkg := obj.Pkg(); pkg != nil && pkg != g.GetTypes() {
+					g.dotImport(pkg.Path())
+				}
+			case nil:
+				if info.Defs[x] == nil {
+					g.fixImports = true
+				}
+			}
+		}
+		return true
+	})
 	var buf strings.Builder
-	if err := format.Node(&buf, g.GetFset(), expr); err != nil {
+	err := format.Node(&buf, g.GetFset(), expr)
+	for ident, name := range renamed {
+		ident.Name = name
+	}
+	if err != nil {
 		log.Fatalln(err)
 	}
-	//ast.Inspect(expr, func(node ast.Node) bool {
-	//	switch x := node.(type) {
-	//	case ast.Expr:
-	//		if named, ok := g.GetInfo().TypeOf(x).(*types.Named); ok {
-	//			pkg := named.Obj().Pkg()
-	//			for _, rawImport := range g.Imports {
-	//				if rawImport.Path == pkg.Path() {
-	//					g.mustImport[rawImport] = struct{}{}
-	//				}
-	//			}
-	//		}
-	//	}
-	//	return true
-	//})
 	return buf.String()
 }
 
+// qualify makes the package at path available to the generated file and
+// returns the name it should be referred to by, which is name unless name is
+// already taken in the generated file by another package or a declaration of
+// the package, in which case the import spec is given an alias. declared is
+// the name declared by the package itself.
+func (g *Generator) qualify(path string, name string, declared string) string {
+	if local, ok := g.locals[path]; ok {
+		return local
+	}
+	local := name
+	for i := 2; g.taken(local); i++ {
+		local = name + strconv.Itoa(i)
+	}
+	imported := &inspect.Import{Path: path}
+	if local != declared {
+		imported.Name = local
+	}
+	g.mustImport[imported] = struct{}{}
+	g.locals[path] = local
+	g.paths[local] = path
+	return local
+}
+
+// taken reports whether name can not be used as a package name in the
+// generated file.
+func (g *Generator) taken(name string) bool {
+	if _, ok := g.paths[name]; ok {
+		return true
+	}
+	return g.GetTypes().Scope().Lookup(name) != nil
+}
+
+// dotImport adds a dot import of the package at path to the generated file.
+func (g *Generator) dotImport(path string) {
+	for imported := range g.mustImport {
+		if imported.Path == path && imported.Name == "." {
+			return
+		}
+	}
+	g.mustImport[&inspect.Import{Name: ".", Path: path}] = struct{}{}
+}
+
+// importPackage makes the package at path available to the generated code and
+// returns the name it should be referred to by.
+func (g *Generator) importPackage(path string) string {
+	return g.importName(path, pathpkg.Base(path))
+}
+
+// importName is like importPackage, name is the declared name of the package,
+// the alias of the package in the scanned files is preferred if there is one.
+func (g *Generator) importName(path string, name string) string {
+	preferred := name
+	for _, imported := range g.Imports {
+		if imported.Path == path && imported.Name != "" && imported.Name != "." && imported.Name != "_" {
+			preferred = imported.Name
+			break
+		}
+	}
+	return g.qualify(path, preferred, name)
+}
+
+// typeString returns the source representation of a resolved type, packages
+// are qualified according to the imports of the generated file.
+func (g *Generator) typeString(typ types.Type) string {
+	return types.TypeString(typ, func(pkg *types.Package) string {
+		if pkg.Path() == g.GetTypes().Path() {
+			return ""
+		}
+		if _, ok := g.locals[pkg.Path()]; !ok {
+			for _, imported := range g.Imports {
+				if imported.Path == pkg.Path() && imported.Name == "." {
+					g.dotImport(pkg.Path())
+					return ""
+				}
+			}
+		}
+		return g.importName(pkg.Path(), pkg.Name())
+	})
+}
+
+// fieldType returns the resolved type of field, the generation is aborted if
+// it could not be resolved since the generated code would be wrong.
+func (g *Generator) fieldType(field *inspect.Field) types.Type {
+	if typ := field.Type; typ != nil && typ != types.Typ[types.Invalid] {
+		return typ
+	}
+	message := fmt.Sprintf("can not resolve type of field %s", field.Name)
+	if err := g.typeError(field.Ast); err != nil {
+		message += ": " + err.Error()
+	}
+	log.Fatalf("%s: %s", g.GetFset().Position(field.Ast.Pos()), message)
+	return nil
+}
+
+// typeError returns the type error explaining why node can not be resolved,
+// which is the first error within node, or the first error of the package
+// otherwise. Errors in the previously generated file are ignored since they
+// are usually caused by changes of the types it is generated from.
+func (g *Generator) typeError(node ast.Node) error {
+	var first error
+	for _, err := range g.GetErrors() {
+		if g.GetFset().Position(err.Pos).Filename == g.output {
+			continue
+		}
+		if node.Pos() <= err.Pos && err.Pos < node.End() {
+			return err
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// rawType returns typ as is, it is used for types written by users in places
+// other than field declarations (e.g. the construct tag), which are not type
+// checked, so that goimports is used to resolve any package they refer to.
+func (g *Generator) rawType(typ string) string {
+	if strings.Contains(typ, ".") {
+		g.fixImports = true
+	}
+	return typ
+}
+
 type constructCtx struct {
-	Field string
-	Type  string
-	Set   string
+	Field    string
+	Type     string
+	Set      string
+	Required bool
+	Atomic   bool
 }
 
 func (g *Generator) genConstruct(receiver string, name string, prefix string, cx []*constructCtx) {
@@ -321,7 +779,7 @@ func (g *Generator) genConstruct(receiver string, name string, prefix string, cx
 	fmt.Fprintf(&g.out, "}")
 }
 
-func inspectField(name string, tag reflect.StructTag) (getter string, hasGetter bool, isRef bool, setter string, hasSetter bool) {
+func inspectField(name string, tag reflect.StructTag) (getter string, hasGetter bool, isRef bool, isCopy bool, setter string, hasSetter bool) {
 	// inspect getter
 	if getterTag, ok := tag.Lookup("getter"); ok {
 		getterParts := strings.Split(getterTag, ",")
@@ -337,6 +795,8 @@ func inspectField(name string, tag reflect.StructTag) (getter string, hasGetter
 					switch strings.TrimSpace(part) {
 					case "ptr", "pointer", "ref", "reference":
 						isRef = true
+					case "copy":
+						isCopy = true
 					}
 				}
 			}
@@ -359,6 +819,41 @@ func inspectField(name string, tag reflect.StructTag) (getter string, hasGetter
 	return
 }
 
+type proxyCtx struct {
+	Field  string
+	Method string
+	IsRef  bool
+}
+
+// parseProxies parses the proxy option of a getter/setter tag, which has the
+// form of `proxy=Field *Field Field:Method`.
+func parseProxies(tag reflect.StructTag, key string) []*proxyCtx {
+	value, ok := tag.Lookup(key)
+	if !ok {
+		return nil
+	}
+	proxies := make([]*proxyCtx, 0, 2)
+	for _, part := range strings.Split(value, ",")[1:] {
+		part = strings.TrimSpace(part)
+		if !strings.HasPrefix(part, "proxy=") {
+			continue
+		}
+		for _, item := range strings.Fields(part[len("proxy="):]) {
+			proxy := new(proxyCtx)
+			if strings.HasPrefix(item, "*") {
+				proxy.IsRef, item = true, item[1:]
+			}
+			if i := strings.IndexByte(item, ':'); i >= 0 {
+				proxy.Field, proxy.Method = item[:i], item[i+1:]
+			} else {
+				proxy.Field = item
+			}
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
 // Converts a string to CamelCase
 func toCamel(s string) string {
 	s = strings.TrimSpace(s)
@@ -401,6 +896,17 @@ func genFieldGetter(w io.Writer, receiver string, method string, field string, t
 		receiver, method, refType(isRef), typ, refValue(isRef), field)
 }
 
+// genFieldCopyGetter generates a getter returning a copy of the field, so that
+// callers can not modify slices, maps or pointed values held by the instance.
+func (g *Generator) genFieldCopyGetter(receiver string, method string, field string, typ string, resolved types.Type, lock *lockCtx) {
+	fmt.Fprintf(&g.out, "func (instance *%s) %s() %s {\n", receiver, method, typ)
+	lock.acquire(&g.out, true)
+	fmt.Fprintf(&g.out, "var value %s\n", typ)
+	g.newCopier().copy(&g.out, "value", "instance."+field, resolved)
+	fmt.Fprintf(&g.out, "return value\n")
+	fmt.Fprintf(&g.out, "}\n")
+}
+
 func refType(isRef bool) string {
 	if isRef {
 		return "*"
@@ -417,9 +923,17 @@ func refValue(isRef bool) string {
 	}
 }
 
-func genFieldSetter(w io.Writer, receiver string, method string, field string, typ string) {
-	fmt.Fprintf(w, "func (instance *%s) %s(value %s) { instance.%s = value }\n",
-		receiver, method, typ, field)
+func genFieldSetter(w io.Writer, receiver string, method string, field string, typ string, track string) {
+	if track != "" {
+		track = "; " + track
+	}
+	fmt.Fprintf(w, "func (instance *%s) %s(value %s) { instance.%s = value%s }\n",
+		receiver, method, typ, field, track)
+}
+
+func genFieldWither(w io.Writer, receiver string, method string, field string, typ string) {
+	fmt.Fprintf(w, "func (instance %s) %s(value %s) %s { instance.%s = value; return instance }\n",
+		receiver, method, typ, receiver, field)
 }
 
 type Directive string
@@ -455,6 +969,24 @@ iter:
 }
 
 func getDirective(list []*ast.Comment, command string) Directive {
+	directive, _ := lookupDirective(list, command)
+	return directive
+}
+
+// lookupDirective is like getDirective, but it also reports whether the
+// directive exists, so that directives without options (e.g. "visc:clone" or
+// "visc:clone()") can be told apart from absent ones.
+func lookupDirective(list []*ast.Comment, command string) (Directive, bool) {
+	if directives := lookupDirectives(list, command); len(directives) > 0 {
+		return directives[0], true
+	}
+	return "", false
+}
+
+// lookupDirectives returns all directives of command in list, for commands
+// which may be specified more than once, e.g. "visc:interface".
+func lookupDirectives(list []*ast.Comment, command string) []Directive {
+	var directives []Directive
 	for _, comment := range list {
 		if comment == nil {
 			continue
@@ -462,23 +994,101 @@ func getDirective(list []*ast.Comment, command string) Directive {
 		var text string
 		switch comment.Text[1] {
 		case '/':
-			text = strings.TrimSpace(comment.Text[2:])
+			text = comment.Text[2:]
 		case '*':
 			text = comment.Text[2 : len(comment.Text)-2]
 		}
+		text = strings.TrimSpace(text)
 		if strings.HasPrefix(text, DirectivePrefix) {
-			text = text[len(DirectivePrefix):]
-			if text[len(text)-1] == ')' {
-				for i, c := range text {
-					if c == '(' {
-						directive := text[:i]
-						if directive == command {
-							return Directive(text[i+1 : len(text)-1])
-						}
-					}
-				}
+			text = strings.TrimSpace(text[len(DirectivePrefix):])
+			if text == command {
+				directives = append(directives, "")
+			} else if strings.HasPrefix(text, command+"(") && strings.HasSuffix(text, ")") {
+				directives = append(directives, Directive(text[len(command)+1:len(text)-1]))
 			}
 		}
 	}
-	return ""
+	return directives
+}
+
+// docComments returns the doc comments of t, which is where directives are.
+// hasDirective reports whether named is declared with the directive command,
+// named is either a target type of this package or a type of a package
+// imported from source, which is generated in the same run when visc is run
+// with package patterns, so the methods of the directive may not exist yet.
+func (g *Generator) hasDirective(named *types.Named, command string) bool {
+	obj := named.Origin().Obj()
+	for _, target := range g.Targets {
+		if target.Named != nil && target.Named.Obj() == obj {
+			_, ok := lookupDirective(docComments(target), command)
+			return ok
+		}
+	}
+	_, ok := lookupDirective(inspect.TypeDoc(obj), command)
+	return ok
+}
+
+func docComments(t *inspect.Type) []*ast.Comment {
+	list := make([]*ast.Comment, 0, 8)
+	if t.Decl.Doc != nil {
+		list = append(list, t.Decl.Doc.List...)
+	}
+	if t.Spec.Doc != nil {
+		list = append(list, t.Spec.Doc.List...)
+	}
+	return list
+}
+
+func structTag(field *inspect.Field) reflect.StructTag {
+	var tag reflect.StructTag
+	if lit := field.Ast.Tag; lit != nil {
+		tag = reflect.StructTag(lit.Value[1 : len(lit.Value)-1])
+	}
+	return tag
+}
+
+// selectField applies the getter/setter tag rules to an arbitrary tag key:
+// "-" opts the field out, "*" selects it with prefix+CamelCase name, any other
+// value is used as the name itself, and a field without the tag is selected
+// with the default name only if all is true.
+func selectField(tag reflect.StructTag, key string, field string, prefix string, all bool) (name string, ok bool) {
+	value, found := tag.Lookup(key)
+	if !found {
+		if all {
+			return prefix + toCamel(field), true
+		}
+		return "", false
+	}
+	switch first := strings.TrimSpace(strings.Split(value, ",")[0]); first {
+	case "-":
+		return "", false
+	case "", "*":
+		return prefix + toCamel(field), true
+	default:
+		return first, true
+	}
+}
+
+// typeParams returns the type parameter list of t as declared, e.g.
+// "[K comparable, V any]", or an empty string if t is not generic.
+func (g *Generator) typeParams(t *inspect.Type) string {
+	if t.Spec.TypeParams == nil {
+		return ""
+	}
+	params := make([]string, 0, len(t.Spec.TypeParams.List))
+	for _, field := range t.Spec.TypeParams.List {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.String())
+		}
+		params = append(params, strings.Join(names, ", ")+" "+g.toString(field.Type))
+	}
+	return "[" + strings.Join(params, ", ") + "]"
+}
+
+// typeArgs returns the type parameter names of t as type arguments, e.g.
+// "[K, V]", or an empty string if t is not generic.
+func typeArgs(t *inspect.Type) string {
+	name := t.Spec.Name.String()
+	return t.String()[len(name):]
 }
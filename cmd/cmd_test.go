@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestEmitStale checks that a file generated for a package which generates
+// nothing now is reported in check mode and removed otherwise, and that a file
+// written by hand is left alone.
+func TestEmitStale(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, goldenOutput)
+	if err := os.WriteFile(path, []byte("// Code generated by visc, DO NOT EDIT.\n\npackage stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := generatedBy(path)
+	if err != nil || !stale {
+		t.Fatalf("generated file is not recognized: %v", err)
+	}
+	check = true
+	upToDate, err := emit(path, nil)
+	check = false
+	if err != nil || upToDate {
+		t.Fatalf("stale file is not reported: %v", err)
+	}
+	if _, err = os.Stat(path); err != nil {
+		t.Fatalf("stale file is removed in check mode: %v", err)
+	}
+	if _, err = emit(path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("stale file is not removed: %v", err)
+	}
+
+	if err = os.WriteFile(path, []byte("package stale\n\n// Code generated by visc, DO NOT EDIT.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if stale, err = generatedBy(path); err != nil || stale {
+		t.Fatalf("file written by hand is taken as generated: %v", err)
+	}
+}
+
+// runEnv is set for the child process started by TestRunPatterns, which runs
+// the command with the newline separated arguments it holds, since the
+// command terminates the process through log.Fatalf.
+const runEnv = "VISC_TEST_RUN"
+
+// runCommand runs the command with args in dir in its own process.
+func runCommand(t *testing.T, dir string, args ...string) (output string, err error) {
+	t.Helper()
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := exec.Command(executable, "-test.run=^TestRunPatterns$")
+	child.Dir = dir
+	child.Env = append(os.Environ(), runEnv+"="+strings.Join(args, "\n"))
+	out, err := child.CombinedOutput()
+	return string(out), err
+}
+
+// TestRunPatterns runs the command with package patterns, checks that every
+// package in testdata is up to date, that packages without any directive
+// are skipped while the files generated for them before are removed, and that
+// methods generated for a package are used by the packages depending on it in
+// the same run.
+func TestRunPatterns(t *testing.T) {
+	if args := os.Getenv(runEnv); args != "" {
+		Command.SetArgs(strings.Split(args, "\n"))
+		if err := Command.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		os.Exit(0)
+	}
+
+	// testdata directories are never matched by wildcards, so every package
+	// is given as a pattern of its own
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"--check", "--output", goldenOutput}
+	for _, dir := range dirs {
+		args = append(args, "./"+filepath.ToSlash(dir))
+	}
+	if output, err := runCommand(t, ".", args...); err != nil || output != "" {
+		t.Fatalf("generated files in testdata are out of date: %v\n%s", err, output)
+	}
+
+	module := t.TempDir()
+	files := map[string]string{
+		"go.mod":            "module example.com/patterns\n\ngo 1.19\n",
+		"a/a.go":            "package a\n\ntype A struct {\n\tname string `getter:\"*\"`\n}\n",
+		"b/b.go":            "package b\n\ntype B struct {\n\tname string\n}\n",
+		"b/" + goldenOutput: "// Code generated by visc, DO NOT EDIT.\n\npackage b\n",
+		"c/c.go":            "package c\n\ntype C struct {\n\tname string\n}\n",
+		"c/" + goldenOutput: "package c\n\n// written by hand\n",
+		"d/d.go":            "package d\n\n// visc:clone\n// visc:equal\ntype Item struct {\n\ttags []string\n}\n",
+		"e/e.go": "package e\n\nimport \"example.com/patterns/d\"\n\n" +
+			"// visc:clone\n// visc:equal\ntype Order struct {\n\titem  *d.Item\n\tvalue d.Item\n}\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(module, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, test := range []struct {
+		args    []string
+		message string
+	}{
+		{
+			args:    []string{"--output", goldenOutput, "a/a.go", "./..."},
+			message: "Files and package patterns can not be specified at the same time.",
+		},
+		{
+			args:    []string{"--output", filepath.Join(module, goldenOutput), "./..."},
+			message: "output should be a path relative to each package directory",
+		},
+		{
+			args:    []string{"--check", "--output", goldenOutput, "./..."},
+			message: "4 generated files are out of date",
+		},
+	} {
+		output, err := runCommand(t, module, test.args...)
+		if err == nil {
+			t.Fatalf("%v succeeded:\n%s", test.args, output)
+		}
+		if !strings.Contains(output, test.message) {
+			t.Fatalf("error %q is expected for %v, got:\n%s", test.message, test.args, output)
+		}
+	}
+	if output, err := runCommand(t, module, "--output", goldenOutput, "./..."); err != nil {
+		t.Fatalf("generation failed: %v\n%s", err, output)
+	}
+	if _, err := os.Stat(filepath.Join(module, "a", goldenOutput)); err != nil {
+		t.Fatalf("file of package a is not generated: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(module, "b", goldenOutput)); !os.IsNotExist(err) {
+		t.Fatalf("stale file of package b is not removed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(module, "c", goldenOutput)); err != nil || string(content) != files["c/"+goldenOutput] {
+		t.Fatalf("file written by hand in package c is changed: %v", err)
+	}
+	if content, err := os.ReadFile(filepath.Join(module, "e", goldenOutput)); err != nil ||
+		!strings.Contains(string(content), "instance.item.Clone()") || !strings.Contains(string(content), "instance.value.Clone()") {
+		t.Fatalf("Clone of package d is not used by package e: %v\n%s", err, content)
+	}
+	if content, err := os.ReadFile(filepath.Join(module, "e", goldenOutput)); err != nil ||
+		!strings.Contains(string(content), "instance.item.Equal(other.item)") || !strings.Contains(string(content), "instance.value.Equal(&other.value)") {
+		t.Fatalf("Equal of package d is not used by package e: %v\n%s", err, content)
+	}
+	if output, err := runCommand(t, module, "--check", "--output", goldenOutput, "./..."); err != nil || output != "" {
+		t.Fatalf("generated files are out of date after generation: %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"log"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genCollection generates the accessors of a slice or map field tagged with
+// `collection:"*"`, name is the plural form used in method names, e.g. for a
+// field `items []T`, LenItems, ItemAt, AppendItems and RangeItems are generated,
+// and for a field `attrs map[K]V`, GetAttr, PutAttr, DeleteAttr and RangeAttrs.
+// The singular form is item if it is not empty, e.g. `collection:"*,item=Datum"`,
+// or derived from name otherwise. Accessors reading the field hold getLock and
+// those modifying it hold setLock if they are not nil, so fn of Range must not
+// call other locked methods. Modifications are recorded as changes of field by
+// track.
+func (g *Generator) genCollection(receiver string, name string, item string, field *inspect.Field, getLock *lockCtx, setLock *lockCtx, track *trackCtx) {
+	singular := item
+	if singular == "" {
+		singular = singularize(name)
+	}
+	mark := track.mark(field.Name)
+	if mark != "" {
+		mark += "\n"
+	}
+	switch u := g.fieldType(field).Underlying().(type) {
+	case *types.Slice:
+		elem := g.typeString(u.Elem())
+		fmt.Fprintf(&g.out, "func (instance *%s) Len%s() int {\n", receiver, name)
+		getLock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "return len(instance.%s)\n", field.Name)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) %sAt(index int) %s {\n", receiver, singular, elem)
+		getLock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "return instance.%s[index]\n", field.Name)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) Append%s(values ...%s) {\n", receiver, name, elem)
+		setLock.acquire(&g.out, false)
+		fmt.Fprintf(&g.out, "instance.%s = append(instance.%s, values...)\n%s", field.Name, field.Name, mark)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) Range%s(fn func(index int, value %s) bool) {\n", receiver, name, elem)
+		getLock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "for index, value := range instance.%s {\nif !fn(index, value) {\nreturn\n}\n}\n", field.Name)
+		fmt.Fprintf(&g.out, "}\n")
+	case *types.Map:
+		key, elem := g.typeString(u.Key()), g.typeString(u.Elem())
+		fmt.Fprintf(&g.out, "func (instance *%s) Get%s(key %s) (%s, bool) {\n", receiver, singular, key, elem)
+		getLock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "value, ok := instance.%s[key]\nreturn value, ok\n", field.Name)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) Put%s(key %s, value %s) {\n", receiver, singular, key, elem)
+		setLock.acquire(&g.out, false)
+		fmt.Fprintf(&g.out, "if instance.%s == nil {\ninstance.%s = make(%s)\n}\n", field.Name, field.Name, g.toString(field.Ast.Type))
+		fmt.Fprintf(&g.out, "instance.%s[key] = value\n%s", field.Name, mark)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) Delete%s(key %s) {\n", receiver, singular, key)
+		setLock.acquire(&g.out, false)
+		fmt.Fprintf(&g.out, "delete(instance.%s, key)\n%s", field.Name, mark)
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "func (instance *%s) Range%s(fn func(key %s, value %s) bool) {\n", receiver, name, key, elem)
+		getLock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "for key, value := range instance.%s {\nif !fn(key, value) {\nreturn\n}\n}\n", field.Name)
+		fmt.Fprintf(&g.out, "}\n")
+	default:
+		log.Fatalf("%s: collection tag is only supported on slice and map fields, but %s is %s",
+			g.GetFset().Position(field.Ast.Pos()), field.Name, g.typeString(field.Type))
+	}
+}
+
+// singularize returns the singular form of an English plural noun with the
+// most common suffix rules, names which are not plural are returned as-is, and
+// the item option of the collection tag names the others.
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "uses") && len(name) > 4 &&
+		!strings.HasSuffix(name, "ouses") && !strings.HasSuffix(name, "auses"):
+		// statuses and buses, but not houses or causes
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && !strings.HasSuffix(name, "us"):
+		// status is not the plural of statu
+		return name[:len(name)-1]
+	}
+	return name
+}
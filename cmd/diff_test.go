@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// numbered returns the lines 1 to n, lines in replace are replaced with the
+// given text.
+func numbered(n int, replace map[int]string) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		if line, ok := replace[i]; ok {
+			b.WriteString(line)
+		} else {
+			b.WriteString(strconv.Itoa(i))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// TestUnifiedDiff compares unifiedDiff with the output of
+// `diff -u --label old --label new old new` for the same files.
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\nc\n",
+			new:  "a\nb\nc\n",
+			want: "",
+		},
+		{
+			name: "change",
+			old:  numbered(10, nil),
+			new:  numbered(10, map[int]string{5: "five"}),
+			want: "--- old\n+++ new\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n",
+		},
+		{
+			name: "separate hunks",
+			old:  numbered(20, nil),
+			new:  numbered(20, map[int]string{2: "X", 18: "X"}),
+			want: "--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+X\n 19\n 20\n",
+		},
+		{
+			name: "merged hunk",
+			old:  numbered(20, nil),
+			new:  numbered(20, map[int]string{5: "X", 11: "X"}),
+			want: "--- old\n+++ new\n@@ -2,13 +2,13 @@\n 2\n 3\n 4\n-5\n+X\n 6\n 7\n 8\n 9\n 10\n-11\n+X\n 12\n 13\n 14\n",
+		},
+		{
+			name: "insert into empty",
+			old:  "",
+			new:  "a\nb\n",
+			want: "--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "delete all",
+			old:  "a\nb\n",
+			new:  "",
+			want: "--- old\n+++ new\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			name: "append",
+			old:  "a\nb\nc\nd\n",
+			new:  "a\nb\nc\nd\ne\n",
+			want: "--- old\n+++ new\n@@ -2,3 +2,4 @@\n b\n c\n d\n+e\n",
+		},
+		{
+			name: "no newline at end",
+			old:  "a\nb\nc",
+			new:  "a\nb\nd",
+			want: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n b\n-c\n\\ No newline at end of file\n+d\n\\ No newline at end of file\n",
+		},
+		{
+			name: "add newline at end",
+			old:  "a\nb",
+			new:  "a\nb\n",
+			want: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := unifiedDiff("old", "new", []byte(test.old), []byte(test.new)); got != test.want {
+				t.Errorf("unifiedDiff() =\n%s\nwant:\n%s", got, test.want)
+			}
+		})
+	}
+}
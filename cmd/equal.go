@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genEqual generates an Equal method comparing t structurally if it has the
+// "visc:equal" directive, fields tagged with `equal:"-"` are not compared.
+// Both instances are compared holding the lock of "visc:all(lock=mu)", if
+// any.
+func (g *Generator) genEqual(t *inspect.Type) {
+	if _, ok := lookupDirective(docComments(t), "equal"); !ok {
+		return
+	}
+	receiver := t.String()
+	c := g.newComparer()
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) Equal(other *%s) bool {\n", receiver, receiver)
+	fmt.Fprintf(&g.out, "if instance == nil || other == nil {\nreturn instance == other\n}\n")
+	if lock := g.allLock(t); lock != nil {
+		// the locks of both instances are held in the order of their
+		// addresses, so that neither Equal on the same instance nor a.Equal(b)
+		// racing with b.Equal(a) deadlocks
+		fmt.Fprintf(&g.out, "if instance == other {\nreturn true\n}\n")
+		fmt.Fprintf(&g.out, "first, second := instance, other\n")
+		unsafe := g.importPackage("unsafe")
+		fmt.Fprintf(&g.out, "if uintptr(%s.Pointer(first)) > uintptr(%s.Pointer(second)) {\n", unsafe, unsafe)
+		fmt.Fprintf(&g.out, "first, second = second, first\n}\n")
+		fmt.Fprintf(&g.out, "first.%s.%s()\ndefer first.%s.%s()\n", lock.Field, lock.rlock(), lock.Field, lock.runlock())
+		fmt.Fprintf(&g.out, "second.%s.%s()\ndefer second.%s.%s()\n", lock.Field, lock.rlock(), lock.Field, lock.runlock())
+	}
+	for _, field := range t.Fields {
+		if field.Name == "_" || bookkeeping(t, field) || structTag(field).Get("equal") == "-" {
+			continue
+		}
+		c.compare(&g.out, "instance."+field.Name, "other."+field.Name, g.fieldType(field))
+	}
+	fmt.Fprintf(&g.out, "return true\n")
+	fmt.Fprintf(&g.out, "}")
+}
+
+// equalable reports whether named has the "visc:equal" directive, its Equal
+// method may not exist yet.
+func (g *Generator) equalable(named *types.Named) bool {
+	return g.hasDirective(named, "equal")
+}
+
+// comparer generates statements which return false from the enclosing
+// function if two values are not equal.
+type comparer struct {
+	g        *Generator
+	vars     int
+	visiting map[*types.Named]bool
+}
+
+func (g *Generator) newComparer() *comparer {
+	return &comparer{
+		g:        g,
+		visiting: make(map[*types.Named]bool),
+	}
+}
+
+func (c *comparer) newVar(prefix string) string {
+	c.vars++
+	return fmt.Sprintf("%s%d", prefix, c.vars)
+}
+
+// equalMethod reports whether typ has an Equal method accepting another value
+// of typ, either as a value or as a pointer (ptrParam).
+func (c *comparer) equalMethod(typ types.Type) (ptrParam bool, ok bool) {
+	named, isNamed := typ.(*types.Named)
+	if !isNamed {
+		return false, false
+	}
+	if c.g.equalable(named) {
+		return true, true
+	}
+	obj, _, _ := types.LookupFieldOrMethod(typ, true, named.Obj().Pkg(), "Equal")
+	method, isFunc := obj.(*types.Func)
+	if !isFunc {
+		return false, false
+	}
+	sig := method.Type().(*types.Signature)
+	if sig.Params().Len() != 1 || sig.Results().Len() != 1 ||
+		!types.Identical(sig.Results().At(0).Type(), types.Typ[types.Bool]) {
+		return false, false
+	}
+	param := sig.Params().At(0).Type()
+	if types.Identical(param, typ) {
+		return false, true
+	}
+	if types.Identical(param, types.NewPointer(typ)) {
+		return true, true
+	}
+	return false, false
+}
+
+// foreign reports whether typ is a named type declared in another package.
+func (c *comparer) foreign(typ types.Type) bool {
+	named, isNamed := typ.(*types.Named)
+	return isNamed && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() != c.g.GetTypes().Path()
+}
+
+// compare generates statements comparing a and b of type typ, both of them
+// must be addressable expressions.
+func (c *comparer) compare(w io.Writer, a string, b string, typ types.Type) {
+	if ptrParam, ok := c.equalMethod(typ); ok {
+		if ptrParam {
+			fmt.Fprintf(w, "if !%s.Equal(&%s) {\nreturn false\n}\n", a, b)
+		} else {
+			fmt.Fprintf(w, "if !%s.Equal(%s) {\nreturn false\n}\n", a, b)
+		}
+		return
+	}
+	if value, isAtomic := atomicValue(typ); isAtomic {
+		va, vb := c.newVar("a"), c.newVar("b")
+		fmt.Fprintf(w, "%s, %s := %s.Load(), %s.Load()\n", va, vb, a, b)
+		c.compare(w, va, vb, value)
+		return
+	}
+	if containsLock(typ) {
+		// locks hold no data to be compared, but the other fields of a struct
+		// and the elements of an array containing them are
+		switch u := typ.Underlying().(type) {
+		case *types.Array:
+		case *types.Struct:
+			if !c.g.accessible(u) {
+				return
+			}
+		default:
+			return
+		}
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Signature:
+		// funcs are only equal to nil, so only whether they are set is compared
+		fmt.Fprintf(w, "if (%s == nil) != (%s == nil) {\nreturn false\n}\n", a, b)
+	case *types.Pointer:
+		if ptrParam, ok := c.equalMethod(u.Elem()); ok {
+			if ptrParam {
+				fmt.Fprintf(w, "if (%s == nil) != (%s == nil) || %s != nil && !%s.Equal(%s) {\nreturn false\n}\n", a, b, a, a, b)
+			} else {
+				fmt.Fprintf(w, "if (%s == nil) != (%s == nil) || %s != nil && !%s.Equal(*%s) {\nreturn false\n}\n", a, b, a, a, b)
+			}
+			return
+		}
+		if c.foreign(u.Elem()) {
+			// values of other packages (e.g. *os.File) are compared by identity
+			fmt.Fprintf(w, "if %s != %s {\nreturn false\n}\n", a, b)
+			return
+		}
+		fmt.Fprintf(w, "if %s != %s {\n", a, b)
+		fmt.Fprintf(w, "if %s == nil || %s == nil {\nreturn false\n}\n", a, b)
+		c.compare(w, "(*"+a+")", "(*"+b+")", u.Elem())
+		fmt.Fprintf(w, "}\n")
+	case *types.Slice:
+		if basic, ok := u.Elem().(*types.Basic); ok && basic.Kind() == types.Byte {
+			fmt.Fprintf(w, "if !%s.Equal(%s, %s) {\nreturn false\n}\n", c.g.importPackage("bytes"), a, b)
+			return
+		}
+		fmt.Fprintf(w, "if len(%s) != len(%s) {\nreturn false\n}\n", a, b)
+		c.compareElems(w, a, b, u.Elem())
+	case *types.Map:
+		if containsLock(u.Elem()) {
+			// values of the map can neither be copied nor addressed
+			c.deepEqual(w, a, b)
+			return
+		}
+		key, va, vb, ok := c.newVar("k"), c.newVar("a"), c.newVar("b"), c.newVar("ok")
+		fmt.Fprintf(w, "if len(%s) != len(%s) {\nreturn false\n}\n", a, b)
+		fmt.Fprintf(w, "for %s, %s := range %s {\n", key, va, a)
+		fmt.Fprintf(w, "%s, %s := %s[%s]\n", vb, ok, b, key)
+		fmt.Fprintf(w, "if !%s {\nreturn false\n}\n", ok)
+		c.compare(w, va, vb, u.Elem())
+		fmt.Fprintf(w, "}\n")
+	case *types.Array:
+		if c.comparable(typ) {
+			fmt.Fprintf(w, "if %s != %s {\nreturn false\n}\n", a, b)
+			return
+		}
+		c.compareElems(w, a, b, u.Elem())
+	case *types.Struct:
+		if c.comparable(typ) {
+			fmt.Fprintf(w, "if %s != %s {\nreturn false\n}\n", a, b)
+			return
+		}
+		named, isNamed := typ.(*types.Named)
+		if c.foreign(typ) || (isNamed && c.visiting[named.Origin()]) {
+			if containsLock(typ) {
+				// pointers are compared so that the locks are not copied
+				c.deepEqual(w, "&"+a, "&"+b)
+			} else {
+				c.deepEqual(w, a, b)
+			}
+			return
+		}
+		if isNamed {
+			c.visiting[named.Origin()] = true
+			defer delete(c.visiting, named.Origin())
+		}
+		for i := 0; i < u.NumFields(); i++ {
+			if field := u.Field(i); field.Name() != "_" {
+				c.compare(w, a+"."+field.Name(), b+"."+field.Name(), field.Type())
+			}
+		}
+	default:
+		if c.comparable(typ) {
+			fmt.Fprintf(w, "if %s != %s {\nreturn false\n}\n", a, b)
+			return
+		}
+		c.deepEqual(w, a, b)
+	}
+}
+
+// compareElems generates a loop comparing the elements of slices or arrays a
+// and b, which is left out if there is nothing to compare, e.g. for locks.
+func (c *comparer) compareElems(w io.Writer, a string, b string, elem types.Type) {
+	var body strings.Builder
+	index := c.newVar("i")
+	c.compare(&body, a+"["+index+"]", b+"["+index+"]", elem)
+	if body.Len() > 0 {
+		fmt.Fprintf(w, "for %s := range %s {\n%s}\n", index, a, body.String())
+	}
+}
+
+// comparable reports whether a and b of typ are equal if and only if a == b,
+// which is not the case for interfaces, pointers to values compared by their
+// content, types with an Equal method and types holding locks.
+func (c *comparer) comparable(typ types.Type) bool {
+	if !types.Comparable(typ) || containsLock(typ) {
+		return false
+	}
+	if _, isParam := typ.(*types.TypeParam); !isParam && types.IsInterface(typ) {
+		// == panics if the dynamic values are not comparable
+		return false
+	}
+	if _, ok := c.equalMethod(typ); ok {
+		return false
+	}
+	switch u := typ.Underlying().(type) {
+	case *types.Pointer:
+		_, ok := c.equalMethod(u.Elem())
+		return !ok && c.foreign(u.Elem())
+	case *types.Array:
+		return c.comparable(u.Elem())
+	case *types.Struct:
+		for i := 0; i < u.NumFields(); i++ {
+			if !c.comparable(u.Field(i).Type()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// deepEqual falls back to reflect.DeepEqual for values which can neither be
+// compared with == nor field by field, e.g. interfaces and structs with
+// unexported fields of other packages.
+func (c *comparer) deepEqual(w io.Writer, a string, b string) {
+	fmt.Fprintf(w, "if !%s.DeepEqual(%s, %s) {\nreturn false\n}\n", c.g.importPackage("reflect"), a, b)
+}
@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// renderEnv is set for the child process started by TestGenerationErrors,
+// which renders the directory it names, since generation errors terminate
+// the process through log.Fatalf.
+const renderEnv = "VISC_TEST_RENDER"
+
+// TestGenerationErrors generates each source in its own process and checks
+// that generation fails with the position of the offending code.
+func TestGenerationErrors(t *testing.T) {
+	if dir := os.Getenv(renderEnv); dir != "" {
+		if _, _, err := render(dir, nil, filepath.Join(dir, goldenOutput)); err != nil {
+			t.Fatal(err)
+		}
+		os.Exit(0)
+	}
+	tests := []struct {
+		name      string
+		source    string
+		generated string // the previously generated file, if any
+		message   string
+		cause     string // the type error explaining the message, if any
+	}{
+		{
+			name: "collection",
+			source: `type T struct {
+	count int ` + "`collection:\"*\"`" + `
+}`,
+			message: "errors.go:4:2: collection tag is only supported on slice and map fields, but count is int",
+		},
+		{
+			name: "proxy missing field",
+			source: `type T struct {
+	inner struct{ Name string } ` + "`getter:\",proxy=Age\"`" + `
+}`,
+			message: "errors.go:4:30: can not proxy inner.Age: type struct{Name string} has no accessible field Age",
+		},
+		{
+			name: "proxy method",
+			source: `import "time"
+
+type T struct {
+	at time.Time ` + "`getter:\",proxy=Unix\"`" + `
+}`,
+			message: "errors.go:6:15: can not proxy at.Unix: Unix is a method, not a field",
+		},
+		{
+			name: "proxy ambiguous",
+			source: `type A struct{ Name string }
+type B struct{ Name string }
+
+type T struct {
+	inner struct {
+		A
+		B
+	} ` + "`getter:\",proxy=Name\"`" + `
+}`,
+			message: "errors.go:10:4: can not proxy inner.Name: ambiguous selector",
+		},
+		{
+			name: "proxy unresolved",
+			source: `type T struct {
+	inner Missing ` + "`getter:\",proxy=Name\"`" + `
+}`,
+			generated: "package errors\n\nfunc (instance *T) Stale() int { return instance.stale }\n",
+			message:   "errors.go:4:2: can not resolve type of field inner: ",
+			cause:     "errors.go:4:8: undefined: Missing",
+		},
+		{
+			name: "duplicated method",
+			source: `type T struct {
+	name  string         ` + "`getter:\"*\"`" + `
+	inner struct{ Name string } ` + "`getter:\",proxy=Name\"`" + `
+}`,
+			message: "errors.go:5:2: method Name is generated more than once",
+		},
+		{
+			name: "options duplicated function",
+			source: `// visc:options
+type S struct {
+	id int
+}
+
+// visc:options
+type T struct {
+	id int
+}`,
+			message: "errors.go:10:2: option function WithId of field T.id is already declared for field S.id",
+		},
+		{
+			name: "wither lock",
+			source: `import "sync"
+
+type T struct {
+	mu    sync.Mutex
+	count int ` + "`wither:\"*\"`" + `
+}`,
+			message: "errors.go:7:12: wither of field count would copy the lock held by T, use a setter instead",
+		},
+		{
+			name: "lock missing",
+			source: `// visc:all(getter=true, lock=mu)
+type T struct {
+	name string
+}`,
+			message: "errors.go:4:6: lock mu is not a field of T",
+		},
+		{
+			name: "lock type",
+			source: `type T struct {
+	mu   int
+	name string ` + "`getter:\"*,lock=mu\"`" + `
+}`,
+			message: "errors.go:5:14: lock mu should be a sync.Mutex or sync.RWMutex, but it is int",
+		},
+		{
+			name: "track missing",
+			source: `// visc:all(setter=true)
+// visc:track
+type T struct {
+	name string
+}`,
+			message: "errors.go:5:6: visc:track needs a field to record changes, e.g. `changes uint64`",
+		},
+		{
+			name: "track type",
+			source: `// visc:all(setter=true)
+// visc:track
+type T struct {
+	changes int
+	name    string
+}`,
+			message: "errors.go:5:6: field changes of visc:track should be an unsigned integer, but it is int",
+		},
+		{
+			name: "track bits",
+			source: `// visc:all(setter=true)
+// visc:track
+type T struct {
+	changes                uint8
+	a, b, c, d, e, f, g, h int
+	i                      int
+}`,
+			message: "errors.go:5:6: 9 fields are tracked, but field changes only has 8 bits",
+		},
+		{
+			name: "track lock",
+			source: `import "sync"
+
+// visc:track
+type T struct {
+	mu      sync.Mutex
+	changes uint8
+	name    string ` + "`setter:\"*,lock=mu\"`" + `
+}`,
+			message: "errors.go:9:17: field name is set holding lock mu, but changes of visc:track are not guarded by it, use visc:all(lock=mu) instead",
+		},
+		{
+			name: "validate unknown rule",
+			source: `// visc:validate
+type T struct {
+	email string ` + "`validate:\"email\"`" + `
+}`,
+			message: "errors.go:5:2: unknown validate rule \"email\"",
+		},
+		{
+			name: "validate type mismatch",
+			source: `// visc:validate
+type T struct {
+	admin bool ` + "`validate:\"oneof=true\"`" + `
+}`,
+			message: "errors.go:5:2: malformed validate rule \"oneof=true\": oneof is not supported on bool",
+		},
+		{
+			name: "validate duplicate oneof",
+			source: `// visc:validate
+type T struct {
+	role string ` + "`validate:\"oneof=admin user admin\"`" + `
+}`,
+			message: "errors.go:5:2: malformed validate rule \"oneof=admin user admin\": admin duplicates admin",
+		},
+		{
+			name: "validate duplicate oneof integer",
+			source: `// visc:validate
+type T struct {
+	mode int ` + "`validate:\"oneof=1 2 0x1\"`" + `
+}`,
+			message: "errors.go:5:2: malformed validate rule \"oneof=1 2 0x1\": 0x1 duplicates 1",
+		},
+		{
+			name: "validate bad regexp",
+			source: `// visc:validate
+type T struct {
+	name string ` + "`validate:\"regexp=[a-\"`" + `
+}`,
+			message: "errors.go:5:2: malformed validate rule \"regexp=[a-\": error parsing regexp",
+		},
+		{
+			name: "validate bad bound",
+			source: `// visc:validate
+type T struct {
+	age int ` + "`validate:\"min=old\"`" + `
+}`,
+			message: "errors.go:5:2: malformed validate rule \"min=old\"",
+		},
+		{
+			name: "fields duplicated constant",
+			source: `type Base struct{ Name string }
+
+// visc:fields(tag=db)
+type T struct {
+	Base
+	name string ` + "`db:\"user_name\"`" + `
+}`,
+			message: "errors.go:8:2: constant TFieldName of field name is already used by field Base.Name",
+		},
+		{
+			name: "json duplicated key",
+			source: `type A struct{ ID int ` + "`json:\"id\"`" + ` }
+type B struct{ Key int ` + "`json:\"id\"`" + ` }
+
+// visc:json
+type T struct {
+	A
+	B
+}`,
+			message: "errors.go:4:16: json key \"id\" of field B.Key is already used by field A.ID",
+		},
+		{
+			name: "sql embedded pointer",
+			source: `type Audit struct{ By string }
+
+// visc:sql
+type T struct {
+	*Audit
+}`,
+			message: "errors.go:3:20: can not scan column \"By\" into field Audit.By through embedded pointer Audit, tag it with `db:\"-\"`",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			source := "package errors\n\n" + test.source + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "errors.go"), []byte(source), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if test.generated != "" {
+				if err := os.WriteFile(filepath.Join(dir, goldenOutput), []byte(test.generated), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			child := exec.Command(os.Args[0], "-test.run=^TestGenerationErrors$")
+			child.Env = append(os.Environ(), renderEnv+"="+dir)
+			output, err := child.CombinedOutput()
+			if err == nil {
+				t.Fatalf("generation succeeded:\n%s", output)
+			}
+			if !strings.Contains(string(output), test.message) {
+				t.Fatalf("error %q is expected, got:\n%s", test.message, output)
+			}
+			if !strings.Contains(string(output), test.cause) {
+				t.Fatalf("cause %q is expected, got:\n%s", test.cause, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genFields generates a constant of type TField for every field of t if it has
+// the "visc:fields(tag=json)" directive, the value of a constant is the name
+// in the given struct tag, or the field name if no tag is specified, and
+// fields whose tag name is "-" are skipped. Fields of embedded structs without
+// a name in the tag are listed in place of the embedded fields.
+func (g *Generator) genFields(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "fields")
+	if !ok {
+		return
+	}
+	key, _ := directive.Lookup("tag")
+	var (
+		typeName = t.Spec.Name.String() + "Field"
+		fields   = g.flattenFields(t, key)
+		names    = make([]string, 0, len(fields))
+		values   = make([]string, 0, len(fields))
+		consts   = make(map[string]*flatField, len(fields))
+	)
+	for _, field := range fields {
+		name := typeName + toCamel(field.Name)
+		if prev, dup := consts[name]; dup {
+			log.Fatalf("%s: constant %s of field %s is already used by field %s",
+				g.GetFset().Position(field.Pos), name, field.Selector, prev.Selector)
+		}
+		consts[name] = field
+		names = append(names, name)
+		values = append(values, field.Key)
+	}
+	fmt.Fprintf(&g.out, "\n\ntype %s string\n", typeName)
+	if len(names) > 0 {
+		fmt.Fprintf(&g.out, "\nconst (\n")
+		for i, name := range names {
+			fmt.Fprintf(&g.out, "%s %s = %q\n", name, typeName, values[i])
+		}
+		fmt.Fprintf(&g.out, ")\n")
+	}
+	fmt.Fprintf(&g.out, "\nfunc %ss() []string {\n", typeName)
+	fmt.Fprintf(&g.out, "return []string{")
+	for _, name := range names {
+		fmt.Fprintf(&g.out, "\nstring(%s),", name)
+	}
+	fmt.Fprintf(&g.out, "\n}\n")
+	fmt.Fprintf(&g.out, "}")
+}
@@ -0,0 +1,172 @@
+package cmd
+
+import (
+	"go/token"
+	"go/types"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// fieldKey returns the name of field in the struct tag key (e.g. json or db),
+// the field name is used if key is empty or the name in the tag is empty, ok
+// is false if the field should be skipped.
+func fieldKey(field *inspect.Field, key string) (name string, ok bool) {
+	return tagKey(field.Name, structTag(field), key)
+}
+
+// tagKey is fieldKey for a field called field with the given tag.
+func tagKey(field string, tag reflect.StructTag, key string) (name string, ok bool) {
+	if field == "_" {
+		return "", false
+	}
+	if key == "" {
+		return field, true
+	}
+	value, found := tag.Lookup(key)
+	if !found {
+		return field, true
+	}
+	name = strings.TrimSpace(strings.Split(value, ",")[0])
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return field, true
+	}
+	return name, true
+}
+
+// flatField is a field of a struct or of a struct embedded in it, Selector is
+// the selector of the field from the instance, e.g. "Base.id", and Pointers
+// are the embedded pointers on the way from the instance to the field.
+type flatField struct {
+	Name     string
+	Key      string
+	Selector string
+	Type     types.Type
+	Tag      reflect.StructTag
+	Pos      token.Pos
+	Depth    int
+	Pointers []*embeddedPointer
+}
+
+// reachable returns the condition that none of the embedded pointers on the
+// way to field from the instance is nil, which is empty if there is none.
+func (field *flatField) reachable() string {
+	conds := make([]string, 0, len(field.Pointers))
+	for _, pointer := range field.Pointers {
+		conds = append(conds, "instance."+pointer.Selector+" != nil")
+	}
+	return strings.Join(conds, " && ")
+}
+
+// embeddedPointer is an embedded pointer to the struct Elem.
+type embeddedPointer struct {
+	Selector string
+	Elem     types.Type
+}
+
+// flattenFields returns the fields of t named by the struct tag key, and the
+// fields of embedded structs without a name in the tag take the place of the
+// embedded fields, which is what encoding/json and most database libraries
+// do. Like the fields of Go structs, a field shadows the fields of the same
+// name embedded deeper, and generation is aborted if two fields of the same
+// name are at the same depth.
+func (g *Generator) flattenFields(t *inspect.Type, key string) []*flatField {
+	fields := make([]*flatField, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		name, ok := fieldKey(field, key)
+		if !ok || bookkeeping(t, field) {
+			continue
+		}
+		flat := &flatField{
+			Name:     field.Name,
+			Key:      name,
+			Selector: field.Name,
+			Type:     g.fieldType(field),
+			Tag:      structTag(field),
+			Pos:      field.Ast.Pos(),
+		}
+		if field.Embedded {
+			fields = append(fields, g.flattenEmbedded(flat, key, map[types.Type]bool{})...)
+		} else {
+			fields = append(fields, flat)
+		}
+	}
+	depths := make(map[string]int, len(fields))
+	for _, field := range fields {
+		if depth, found := depths[field.Key]; !found || field.Depth < depth {
+			depths[field.Key] = field.Depth
+		}
+	}
+	var (
+		flattened = fields[:0]
+		keys      = make(map[string]*flatField, len(fields))
+	)
+	for _, field := range fields {
+		if field.Depth != depths[field.Key] {
+			continue
+		}
+		if prev, dup := keys[field.Key]; dup {
+			log.Fatalf("%s: %s key %q of field %s is already used by field %s",
+				g.GetFset().Position(field.Pos), key, field.Key, field.Selector, prev.Selector)
+		}
+		keys[field.Key] = field
+		flattened = append(flattened, field)
+	}
+	return flattened
+}
+
+// flattenEmbedded returns the fields of the struct embedded as field, or field
+// itself if it is not a struct or its name is given in the tag key.
+func (g *Generator) flattenEmbedded(field *flatField, key string, seen map[types.Type]bool) []*flatField {
+	if name, _ := tagKey("", field.Tag, key); name != "" {
+		return []*flatField{field}
+	}
+	typ, pointers := field.Type, field.Pointers
+	if ptr, isPtr := typ.Underlying().(*types.Pointer); isPtr {
+		typ = ptr.Elem()
+		pointers = append(pointers[:len(pointers):len(pointers)], &embeddedPointer{Selector: field.Selector, Elem: typ})
+	}
+	structType, isStruct := typ.Underlying().(*types.Struct)
+	if !isStruct {
+		return []*flatField{field}
+	}
+	if seen[typ] {
+		// a struct embedding itself through pointers has no more fields
+		return nil
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+	fields := make([]*flatField, 0, structType.NumFields())
+	for i := 0; i < structType.NumFields(); i++ {
+		nested := structType.Field(i)
+		if !nested.Exported() && nested.Pkg().Path() != g.GetTypes().Path() {
+			continue
+		}
+		tag := reflect.StructTag(structType.Tag(i))
+		name, ok := tagKey(nested.Name(), tag, key)
+		if !ok {
+			continue
+		}
+		flat := &flatField{
+			Name:     nested.Name(),
+			Key:      name,
+			Selector: field.Selector + "." + nested.Name(),
+			Type:     nested.Type(),
+			Tag:      tag,
+			Pos:      nested.Pos(),
+			Depth:    field.Depth + 1,
+			Pointers: pointers,
+		}
+		if nested.Embedded() {
+			fields = append(fields, g.flattenEmbedded(flat, key, seen)...)
+		} else {
+			fields = append(fields, flat)
+		}
+	}
+	return fields
+}
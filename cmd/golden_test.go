@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the generated files in testdata")
+
+// goldenOutput is the name of the generated file in each testdata package.
+const goldenOutput = "visc.gen.go"
+
+// TestGolden generates every package in testdata, compares the code with the
+// generated file checked in next to it, then vets the packages and runs their
+// tests, which exercise the generated code.
+func TestGolden(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatal("no testdata package is found")
+	}
+	packages := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		path := filepath.Join(dir, goldenOutput)
+		packages = append(packages, "./"+filepath.ToSlash(dir))
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			code, empty, err := render(dir, nil, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if empty {
+				t.Fatalf("nothing is generated for %s", dir)
+			}
+			if *update {
+				if err := os.WriteFile(path, code, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(golden, code) {
+				t.Errorf("%s is out of date, run go test -update:\n%s",
+					path, unifiedDiff(path, path+" (generated)", golden, code))
+			}
+		})
+	}
+	if t.Failed() {
+		return
+	}
+	// json tags on unexported fields are intended
+	vet := exec.Command("go", append([]string{"vet", "-structtag=false"}, packages...)...)
+	if output, err := vet.CombinedOutput(); err != nil {
+		t.Fatalf("go vet: %v\n%s", err, output)
+	}
+	test := exec.Command("go", append([]string{"test", "-vet=off"}, packages...)...)
+	if output, err := test.CombinedOutput(); err != nil {
+		t.Fatalf("go test: %v\n%s", err, output)
+	}
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// accessorCtx collects the signatures of the getters and setters generated for
+// a type, in the order they are generated.
+type accessorCtx struct {
+	Getters []string
+	Setters []string
+}
+
+func (a *accessorCtx) getter(method string, typ string) {
+	a.Getters = append(a.Getters, fmt.Sprintf("%s() %s", method, typ))
+}
+
+func (a *accessorCtx) setter(method string, typ string) {
+	a.Setters = append(a.Setters, fmt.Sprintf("%s(value %s)", method, typ))
+}
+
+// genInterface generates an interface for every
+// "visc:interface(name=TReader, getters=true, setters=false)" directive of t,
+// whose method set is exactly the getters and/or setters generated for t.
+func (g *Generator) genInterface(t *inspect.Type, accessors *accessorCtx) {
+	for _, directive := range lookupDirectives(docComments(t), "interface") {
+		name, found := directive.Lookup("name")
+		if !found || name == "" {
+			name = t.Spec.Name.String() + "Interface"
+		}
+		getters, setters := true, true
+		if opt, found := directive.Lookup("getters"); found {
+			if b, err := strconv.ParseBool(opt); err == nil {
+				getters = b
+			}
+		}
+		if opt, found := directive.Lookup("setters"); found {
+			if b, err := strconv.ParseBool(opt); err == nil {
+				setters = b
+			}
+		}
+		fmt.Fprintf(&g.out, "\n\ntype %s%s interface {\n", name, g.typeParams(t))
+		if getters {
+			for _, method := range accessors.Getters {
+				fmt.Fprintf(&g.out, "%s\n", method)
+			}
+		}
+		if setters {
+			for _, method := range accessors.Setters {
+				fmt.Fprintf(&g.out, "%s\n", method)
+			}
+		}
+		fmt.Fprintf(&g.out, "}")
+	}
+}
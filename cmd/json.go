@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genJSON generates MarshalJSON and UnmarshalJSON for t if it has the
+// "visc:json" directive, the object is encoded field by field with the names
+// in json tags, which also support the omitempty and string options, so that
+// unexported fields round-trip through encoding/json. Like encoding/json,
+// fields of embedded structs without a name in json tags are encoded as fields
+// of t. The fields are read and written holding the lock of
+// "visc:all(lock=mu)", if any.
+func (g *Generator) genJSON(t *inspect.Type) {
+	if _, ok := lookupDirective(docComments(t), "json"); !ok {
+		return
+	}
+	type jsonCtx struct {
+		Field     *flatField
+		Type      types.Type
+		Atomic    bool
+		OmitEmpty bool
+		String    bool
+	}
+	cx := make([]*jsonCtx, 0, len(t.Fields))
+	for _, field := range g.flattenFields(t, "json") {
+		ctx := &jsonCtx{
+			Field:     field,
+			Type:      field.Type,
+			OmitEmpty: hasTagOption(field.Tag, "json", "omitempty"),
+		}
+		if value, isAtomic := atomicValue(ctx.Type); isAtomic {
+			ctx.Type, ctx.Atomic = value, true
+		} else if containsLock(ctx.Type) {
+			// locks hold no data to be encoded
+			continue
+		}
+		// like encoding/json, the string option only applies to strings,
+		// numbers and booleans
+		if basic, isBasic := ctx.Type.Underlying().(*types.Basic); isBasic &&
+			basic.Info()&(types.IsString|types.IsNumeric|types.IsBoolean) != 0 {
+			ctx.String = hasTagOption(field.Tag, "json", "string")
+		}
+		cx = append(cx, ctx)
+	}
+	var (
+		name     = t.Spec.Name.String()
+		receiver = t.String()
+		jsonName = g.importPackage("encoding/json")
+	)
+	lock := g.allLock(t)
+	fmt.Fprintf(&g.out, "\n\nfunc (instance %s) MarshalJSON() ([]byte, error) {\n", g.valueReceiver(t))
+	fmt.Fprintf(&g.out, "var buf %s.Buffer\n", g.importPackage("bytes"))
+	fmt.Fprintf(&g.out, "buf.WriteByte('{')\n")
+	if len(cx) > 0 {
+		fmt.Fprintf(&g.out, "var (\ndata []byte\nerr error\n)\n")
+		lock.acquire(&g.out, true)
+	}
+	for _, ctx := range cx {
+		value := "instance." + ctx.Field.Selector
+		if ctx.Atomic {
+			value += ".Load()"
+		}
+		// fields of nil embedded pointers are omitted as encoding/json does
+		conds := make([]string, 0, 2)
+		if reachable := ctx.Field.reachable(); reachable != "" {
+			conds = append(conds, reachable)
+		}
+		if ctx.OmitEmpty {
+			if nonEmpty := jsonNonEmpty(value, ctx.Type); nonEmpty != "" {
+				conds = append(conds, nonEmpty)
+			}
+		}
+		if len(conds) > 0 {
+			fmt.Fprintf(&g.out, "if %s {\n", strings.Join(conds, " && "))
+		}
+		fmt.Fprintf(&g.out, "if data, err = %s.Marshal(%s); err != nil {\nreturn nil, err\n}\n", jsonName, value)
+		if ctx.String {
+			fmt.Fprintf(&g.out, "if data, err = %s.Marshal(string(data)); err != nil {\nreturn nil, err\n}\n", jsonName)
+		}
+		key, _ := json.Marshal(ctx.Field.Key)
+		fmt.Fprintf(&g.out, "if buf.Len() > 1 {\nbuf.WriteByte(',')\n}\n")
+		fmt.Fprintf(&g.out, "buf.WriteString(%q)\n", string(key)+":")
+		fmt.Fprintf(&g.out, "buf.Write(data)\n")
+		if len(conds) > 0 {
+			fmt.Fprintf(&g.out, "}\n")
+		}
+	}
+	fmt.Fprintf(&g.out, "buf.WriteByte('}')\n")
+	fmt.Fprintf(&g.out, "return buf.Bytes(), nil\n")
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) UnmarshalJSON(data []byte) error {\n", receiver)
+	fmt.Fprintf(&g.out, "var fields map[string]%s.RawMessage\n", jsonName)
+	fmt.Fprintf(&g.out, "if err := %s.Unmarshal(data, &fields); err != nil {\nreturn err\n}\n", jsonName)
+	if len(cx) > 0 {
+		lock.acquire(&g.out, false)
+		fmt.Fprintf(&g.out, "for key, value := range fields {\n")
+		fmt.Fprintf(&g.out, "switch key {\n")
+		for _, ctx := range cx {
+			fmt.Fprintf(&g.out, "case %q:\n", ctx.Field.Key)
+			if ctx.String {
+				// like encoding/json, null is a no-op with the string option
+				fmt.Fprintf(&g.out, "if string(value) == \"null\" {\nbreak\n}\n")
+			}
+			for _, pointer := range ctx.Field.Pointers {
+				fmt.Fprintf(&g.out, "if instance.%s == nil {\ninstance.%s = new(%s)\n}\n",
+					pointer.Selector, pointer.Selector, g.typeString(pointer.Elem))
+			}
+			target := "&instance." + ctx.Field.Selector
+			if ctx.Atomic {
+				fmt.Fprintf(&g.out, "var v %s\n", g.typeString(ctx.Type))
+				target = "&v"
+			}
+			if ctx.String {
+				fmt.Fprintf(&g.out, "var s string\n")
+				fmt.Fprintf(&g.out, "if err := %s.Unmarshal(value, &s); err != nil {\n", jsonName)
+				fmt.Fprintf(&g.out, "return %s.Errorf(\"%s: field %s: %%w\", err)\n", g.importPackage("fmt"), name, ctx.Field.Selector)
+				fmt.Fprintf(&g.out, "}\n")
+				fmt.Fprintf(&g.out, "value = []byte(s)\n")
+			}
+			fmt.Fprintf(&g.out, "if err := %s.Unmarshal(value, %s); err != nil {\n", jsonName, target)
+			fmt.Fprintf(&g.out, "return %s.Errorf(\"%s: field %s: %%w\", err)\n", g.importPackage("fmt"), name, ctx.Field.Selector)
+			fmt.Fprintf(&g.out, "}\n")
+			if ctx.Atomic {
+				fmt.Fprintf(&g.out, "instance.%s.Store(v)\n", ctx.Field.Selector)
+			}
+		}
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "return nil\n")
+	fmt.Fprintf(&g.out, "}")
+}
+
+// jsonNonEmpty returns the condition under which encoding/json does not
+// regard value of typ as empty for the omitempty option, or "" if it is never
+// empty.
+func jsonNonEmpty(value string, typ types.Type) string {
+	switch u := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return value
+		case u.Info()&types.IsString != 0:
+			return value + ` != ""`
+		case u.Info()&types.IsNumeric != 0:
+			return value + " != 0"
+		}
+	case *types.Pointer, *types.Signature, *types.Chan:
+		return value + " != nil"
+	case *types.Interface:
+		if _, isParam := typ.(*types.TypeParam); !isParam {
+			return value + " != nil"
+		}
+	case *types.Slice, *types.Map, *types.Array:
+		return "len(" + value + ") != 0"
+	}
+	return ""
+}
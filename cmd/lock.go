@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"io"
+	"log"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// lockCtx is a mutex field guarding generated getters and setters, RW is true
+// if it is a sync.RWMutex, in which case getters only take the read lock.
+type lockCtx struct {
+	Field string
+	RW    bool
+}
+
+func (lock *lockCtx) rlock() string {
+	if lock.RW {
+		return "RLock"
+	}
+	return "Lock"
+}
+
+func (lock *lockCtx) runlock() string {
+	if lock.RW {
+		return "RUnlock"
+	}
+	return "Unlock"
+}
+
+// acquire writes the statements holding lock until the generated method
+// returns, read takes the read lock of a sync.RWMutex, and nothing is written
+// if lock is nil.
+func (lock *lockCtx) acquire(w io.Writer, read bool) {
+	if lock == nil {
+		return
+	}
+	if read {
+		fmt.Fprintf(w, "instance.%s.%s()\ndefer instance.%s.%s()\n", lock.Field, lock.rlock(), lock.Field, lock.runlock())
+	} else {
+		fmt.Fprintf(w, "instance.%s.Lock()\ndefer instance.%s.Unlock()\n", lock.Field, lock.Field)
+	}
+}
+
+// allLock returns the lock of the "visc:all(lock=mu)" directive of t, which
+// is nil if there is none.
+func (g *Generator) allLock(t *inspect.Type) *lockCtx {
+	directive, ok := lookupDirective(docComments(t), "all")
+	if !ok {
+		return nil
+	}
+	name, found := directive.Lookup("lock")
+	if !found || name == "" {
+		return nil
+	}
+	return g.lookupLock(t, name, t.Spec.Name.Pos())
+}
+
+// valueReceiver returns the receiver of methods which are meant to be called on
+// both values and pointers of t, e.g. String and MarshalJSON. It is a pointer
+// if t must not be copied, or if its fields are guarded by the lock of
+// "visc:all(lock=mu)", since copying t would read them without the lock.
+func (g *Generator) valueReceiver(t *inspect.Type) string {
+	if containsLock(t.Named) || g.allLock(t) != nil {
+		return "*" + t.String()
+	}
+	return t.String()
+}
+
+// lookupLock verifies that t has a field called name of type sync.Mutex or
+// sync.RWMutex (or pointers to them), generation is aborted otherwise.
+func (g *Generator) lookupLock(t *inspect.Type, name string, pos token.Pos) *lockCtx {
+	position := g.GetFset().Position(pos)
+	if t.Named == nil {
+		log.Fatalf("%s: can not resolve type %s to verify lock %s", position, t.Spec.Name, name)
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t.Named, true, g.GetTypes(), name)
+	field, ok := obj.(*types.Var)
+	if !ok {
+		log.Fatalf("%s: lock %s is not a field of %s", position, name, t.Spec.Name)
+	}
+	typ := field.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if named, ok := typ.(*types.Named); ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == "sync" {
+		switch named.Obj().Name() {
+		case "Mutex":
+			return &lockCtx{Field: name}
+		case "RWMutex":
+			return &lockCtx{Field: name, RW: true}
+		}
+	}
+	log.Fatalf("%s: lock %s should be a sync.Mutex or sync.RWMutex, but it is %s",
+		position, name, g.typeString(field.Type()))
+	return nil
+}
+
+func genLockedFieldGetter(w io.Writer, receiver string, method string, field string, typ string, isRef bool, lock *lockCtx) {
+	fmt.Fprintf(w, "func (instance *%s) %s() %s%s {\n", receiver, method, refType(isRef), typ)
+	lock.acquire(w, true)
+	fmt.Fprintf(w, "return %sinstance.%s\n", refValue(isRef), field)
+	fmt.Fprintf(w, "}\n")
+}
+
+func genLockedFieldSetter(w io.Writer, receiver string, method string, field string, typ string, lock *lockCtx, track string) {
+	fmt.Fprintf(w, "func (instance *%s) %s(value %s) {\n", receiver, method, typ)
+	lock.acquire(w, false)
+	fmt.Fprintf(w, "instance.%s = value\n", field)
+	if track != "" {
+		fmt.Fprintf(w, "%s\n", track)
+	}
+	fmt.Fprintf(w, "}\n")
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genMap generates ToMap and FromMap for t if it has the "visc:map(tag=json)"
+// directive, keys are named by the given struct tag ("map" by default), and
+// FromMap reports the field whose value has a mismatched type. Fields of
+// embedded structs without a name in the tag are keyed in place of the
+// embedded fields, and those behind nil embedded pointers are left out of
+// ToMap. Both methods hold the lock of "visc:all(lock=mu)", if any.
+func (g *Generator) genMap(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "map")
+	if !ok {
+		return
+	}
+	tag, found := directive.Lookup("tag")
+	if !found || tag == "" {
+		tag = "map"
+	}
+	type keyCtx struct {
+		Field  *flatField
+		Type   types.Type
+		Atomic bool
+	}
+	var (
+		fields   = g.flattenFields(t, tag)
+		cx       = make([]*keyCtx, 0, len(fields))
+		pointers bool
+	)
+	for _, field := range fields {
+		ctx := &keyCtx{Field: field, Type: field.Type}
+		if value, isAtomic := atomicValue(ctx.Type); isAtomic {
+			ctx.Type, ctx.Atomic = value, true
+		} else if containsLock(ctx.Type) {
+			// locks are no data to be put into maps
+			continue
+		}
+		if len(field.Pointers) > 0 {
+			pointers = true
+		}
+		cx = append(cx, ctx)
+	}
+	var (
+		name     = t.Spec.Name.String()
+		receiver = t.String()
+		lock     = g.allLock(t)
+	)
+	value := func(ctx *keyCtx) string {
+		value := "instance." + ctx.Field.Selector
+		if ctx.Atomic {
+			value += ".Load()"
+		}
+		return value
+	}
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) ToMap() map[string]any {\n", receiver)
+	if len(cx) > 0 {
+		lock.acquire(&g.out, true)
+	}
+	if pointers {
+		fmt.Fprintf(&g.out, "m := map[string]any{")
+	} else {
+		fmt.Fprintf(&g.out, "return map[string]any{")
+	}
+	for _, ctx := range cx {
+		if len(ctx.Field.Pointers) == 0 {
+			fmt.Fprintf(&g.out, "\n%q: %s,", ctx.Field.Key, value(ctx))
+		}
+	}
+	fmt.Fprintf(&g.out, "\n}\n")
+	if pointers {
+		for _, ctx := range cx {
+			if len(ctx.Field.Pointers) == 0 {
+				continue
+			}
+			fmt.Fprintf(&g.out, "if %s {\nm[%q] = %s\n}\n", ctx.Field.reachable(), ctx.Field.Key, value(ctx))
+		}
+		fmt.Fprintf(&g.out, "return m\n")
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) FromMap(m map[string]any) error {\n", receiver)
+	if len(cx) > 0 {
+		lock.acquire(&g.out, false)
+	}
+	for _, ctx := range cx {
+		typ := g.typeString(ctx.Type)
+		fmt.Fprintf(&g.out, "if value, ok := m[%q]; ok {\n", ctx.Field.Key)
+		fmt.Fprintf(&g.out, "if v, ok := value.(%s); ok {\n", typ)
+		for _, pointer := range ctx.Field.Pointers {
+			fmt.Fprintf(&g.out, "if instance.%s == nil {\ninstance.%s = new(%s)\n}\n",
+				pointer.Selector, pointer.Selector, g.typeString(pointer.Elem))
+		}
+		if ctx.Atomic {
+			fmt.Fprintf(&g.out, "instance.%s.Store(v)\n", ctx.Field.Selector)
+		} else {
+			fmt.Fprintf(&g.out, "instance.%s = v\n", ctx.Field.Selector)
+		}
+		if nilable(ctx.Type) && !ctx.Atomic {
+			// untyped nil is accepted as the zero value of nilable types, which
+			// leaves nil embedded pointers as they are
+			fmt.Fprintf(&g.out, "} else if value == nil {\n")
+			if reachable := ctx.Field.reachable(); reachable != "" {
+				fmt.Fprintf(&g.out, "if %s {\ninstance.%s = nil\n}\n", reachable, ctx.Field.Selector)
+			} else {
+				fmt.Fprintf(&g.out, "instance.%s = nil\n", ctx.Field.Selector)
+			}
+		}
+		fmt.Fprintf(&g.out, "} else {\n")
+		fmt.Fprintf(&g.out, "return %s.Errorf(%q, value)\n",
+			g.importPackage("fmt"), name+": field "+ctx.Field.Selector+": expected "+typ+", got %T")
+		fmt.Fprintf(&g.out, "}\n")
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "return nil\n")
+	fmt.Fprintf(&g.out, "}")
+}
+
+// nilable reports whether nil can be assigned to values of typ.
+func nilable(typ types.Type) bool {
+	if _, isParam := typ.(*types.TypeParam); isParam {
+		return false
+	}
+	switch typ.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Interface, *types.Signature, *types.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genOptions generates functional options for t if it has the
+// "visc:options(name=Option, prefix=With)" directive, every field is selected
+// unless it is tagged with `option:"-"`, and `option:"WithName"` can be used
+// to specify the name of its option function. Locks and the bitmask of
+// "visc:track" are left out, and options of sync/atomic fields take the
+// values they hold. Option functions are declared in the package scope, so
+// generation is aborted if two fields get option functions of the same name.
+func (g *Generator) genOptions(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "options")
+	if !ok {
+		return
+	}
+	name, found := directive.Lookup("name")
+	if !found || name == "" {
+		name = t.Spec.Name.String() + "Option"
+	}
+	prefix, found := directive.Lookup("prefix")
+	if !found {
+		prefix = "With"
+	}
+	var (
+		receiver = t.String()
+		params   = g.typeParams(t)
+		option   = name + typeArgs(t)
+	)
+	fmt.Fprintf(&g.out, "\n\ntype %s%s func(*%s)\n", name, params, receiver)
+	for _, field := range t.Fields {
+		if field.Embedded || bookkeeping(t, field) {
+			continue
+		}
+		value, isAtomic := atomicValue(field.Type)
+		if !isAtomic && containsLock(field.Type) {
+			continue
+		}
+		method, ok := selectField(structTag(field), "option", field.Name, prefix, true)
+		if !ok {
+			continue
+		}
+		selector := t.Spec.Name.String() + "." + field.Name
+		if prev, dup := g.options[method]; dup {
+			log.Fatalf("%s: option function %s of field %s is already declared for field %s",
+				g.GetFset().Position(field.Ast.Pos()), method, selector, prev)
+		}
+		g.options[method] = selector
+		if isAtomic {
+			fmt.Fprintf(&g.out, "\nfunc %s%s(value %s) %s {\n", method, params, g.typeString(value), option)
+			fmt.Fprintf(&g.out, "return func(instance *%s) { instance.%s.Store(value) }\n", receiver, field.Name)
+		} else {
+			fmt.Fprintf(&g.out, "\nfunc %s%s(value %s) %s {\n", method, params, g.toString(field.Ast.Type), option)
+			fmt.Fprintf(&g.out, "return func(instance *%s) { instance.%s = value }\n", receiver, field.Name)
+		}
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "\nfunc New%s%s(opts ...%s) *%s {\n", t.Spec.Name.String(), params, option, receiver)
+	fmt.Fprintf(&g.out, "instance := new(%s)\n", receiver)
+	fmt.Fprintf(&g.out, "for _, opt := range opts {\nopt(instance)\n}\n")
+	fmt.Fprintf(&g.out, "return instance\n")
+	fmt.Fprintf(&g.out, "}")
+}
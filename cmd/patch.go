@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genPatch generates a patch type for t if it has the
+// "visc:patch(name=TPatch)" directive, every field of the patch is a pointer
+// to the new value of the corresponding field of t, and nil means unchanged.
+// Apply applies a patch to t, and DiffT returns the patch turning a into b,
+// fields tagged with `patch:"-"` are not patched.
+func (g *Generator) genPatch(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "patch")
+	if !ok {
+		return
+	}
+	name, found := directive.Lookup("name")
+	if !found || name == "" {
+		name = t.Spec.Name.String() + "Patch"
+	}
+	type patchCtx struct {
+		Field  *inspect.Field
+		Name   string
+		Type   string
+		Atomic bool
+	}
+	var (
+		cx    = make([]*patchCtx, 0, len(t.Fields))
+		names = make(map[string]*inspect.Field, len(t.Fields))
+	)
+	for _, field := range t.Fields {
+		if field.Name == "_" || bookkeeping(t, field) || strings.TrimSpace(structTag(field).Get("patch")) == "-" {
+			continue
+		}
+		ctx := &patchCtx{Field: field, Name: toCamel(field.Name)}
+		if value, isAtomic := atomicValue(field.Type); isAtomic {
+			ctx.Type, ctx.Atomic = g.typeString(value), true
+		} else if containsLock(field.Type) {
+			// locks are never patched
+			continue
+		} else {
+			ctx.Type = g.toString(field.Ast.Type)
+		}
+		if prev, dup := names[ctx.Name]; dup {
+			log.Fatalf("%s: patch field %s of field %s is already used by field %s",
+				g.GetFset().Position(field.Ast.Pos()), ctx.Name, field.Name, prev.Name)
+		}
+		names[ctx.Name] = field
+		cx = append(cx, ctx)
+	}
+	var (
+		receiver = t.String()
+		params   = g.typeParams(t)
+		patch    = name + typeArgs(t)
+		c        = g.newComparer()
+		lock     = g.allLock(t)
+		track    = g.tracks[t]
+	)
+	fmt.Fprintf(&g.out, "\n\ntype %s%s struct {\n", name, params)
+	for _, ctx := range cx {
+		key, ok := fieldKey(ctx.Field, "json")
+		if !ok {
+			key = "-"
+		} else {
+			key += ",omitempty"
+		}
+		fmt.Fprintf(&g.out, "%s *%s `json:%q`\n", ctx.Name, ctx.Type, key)
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) Apply(patch %s) {\n", receiver, patch)
+	lock.acquire(&g.out, false)
+	for _, ctx := range cx {
+		fmt.Fprintf(&g.out, "if patch.%s != nil {\n", ctx.Name)
+		if ctx.Atomic {
+			fmt.Fprintf(&g.out, "instance.%s.Store(*patch.%s)\n", ctx.Field.Name, ctx.Name)
+		} else {
+			fmt.Fprintf(&g.out, "instance.%s = *patch.%s\n", ctx.Field.Name, ctx.Name)
+		}
+		if mark := track.mark(ctx.Field.Name); mark != "" {
+			fmt.Fprintf(&g.out, "%s\n", mark)
+		}
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc Diff%s%s(a, b *%s) %s {\n", t.Spec.Name.String(), params, receiver, patch)
+	fmt.Fprintf(&g.out, "var patch %s\n", patch)
+	// the values of b are read before holding the lock of a, so that neither
+	// Diff(a, a) nor Diff(a, b) racing with Diff(b, a) deadlocks
+	values := make([]string, len(cx))
+	for i, ctx := range cx {
+		if lock == nil {
+			values[i] = "b." + ctx.Field.Name
+		} else {
+			values[i] = "b" + ctx.Name
+		}
+	}
+	if lock != nil {
+		fmt.Fprintf(&g.out, "b.%s.%s()\n", lock.Field, lock.rlock())
+		for i, ctx := range cx {
+			if ctx.Atomic {
+				fmt.Fprintf(&g.out, "%s := b.%s.Load()\n", values[i], ctx.Field.Name)
+			} else {
+				fmt.Fprintf(&g.out, "%s := b.%s\n", values[i], ctx.Field.Name)
+			}
+		}
+		fmt.Fprintf(&g.out, "b.%s.%s()\n", lock.Field, lock.runlock())
+		fmt.Fprintf(&g.out, "a.%s.%s()\ndefer a.%s.%s()\n", lock.Field, lock.rlock(), lock.Field, lock.runlock())
+	}
+	for i, ctx := range cx {
+		va, vb := "a."+ctx.Field.Name, values[i]
+		typ := g.fieldType(ctx.Field)
+		if ctx.Atomic {
+			typ, _ = atomicValue(typ)
+			if lock == nil {
+				vb += ".Load()"
+			}
+			fmt.Fprintf(&g.out, "if a, b := %s.Load(), %s; ", va, vb)
+			va, vb = "a", "b"
+		} else {
+			fmt.Fprintf(&g.out, "if ")
+		}
+		if ptrParam, ok := c.equalMethod(typ); ok {
+			if ptrParam {
+				vb = "&" + vb
+			}
+			fmt.Fprintf(&g.out, "!%s.Equal(%s) {\n", va, vb)
+		} else if c.comparable(typ) {
+			fmt.Fprintf(&g.out, "%s != %s {\n", va, vb)
+		} else {
+			fmt.Fprintf(&g.out, "!%s.DeepEqual(%s, %s) {\n", g.importPackage("reflect"), va, vb)
+		}
+		fmt.Fprintf(&g.out, "value := %s\n", strings.TrimPrefix(vb, "&"))
+		fmt.Fprintf(&g.out, "patch.%s = &value\n", ctx.Name)
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "return patch\n")
+	fmt.Fprintf(&g.out, "}")
+}
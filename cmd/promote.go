@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// promoteCtx is an embedded field tagged with the promote option, Index is its
+// index in the struct.
+type promoteCtx struct {
+	Index  int
+	Getter bool
+	Setter bool
+}
+
+// genPromoted generates forwarding getters and setters for fields promoted
+// through embedded fields. A field is only forwarded if the selector of its
+// name on t denotes it, which follows the rules of the Go spec: shallower
+// fields shadow deeper ones, and fields of the same name at the same depth
+// are ambiguous and not promoted at all. Methods in exists are not generated
+// again since a field of t itself always wins over promoted fields, and
+// promoted fields are accessed holding lock if it is not nil. Setters record
+// the change of the embedded field by track.
+func (g *Generator) genPromoted(t *inspect.Type, promotes []*promoteCtx, getPrefix string, setPrefix string, exists map[string]bool, accessors *accessorCtx, lock *lockCtx, track *trackCtx) {
+	if t.Named == nil {
+		return
+	}
+	receiver := t.String()
+	for _, promote := range promotes {
+		embedded := t.Fields[promote.Index]
+		g.walkPromoted(g.fieldType(embedded), []int{promote.Index}, map[types.Type]bool{}, func(path []int, field *types.Var) {
+			if field.Name() == "_" || (!field.Exported() && field.Pkg().Path() != g.GetTypes().Path()) {
+				return
+			}
+			// methods of t are not considered, they may have been generated
+			// by a previous run of visc
+			obj, index, _ := types.LookupFieldOrMethod(t.Named.Underlying(), true, g.GetTypes(), field.Name())
+			if obj == nil || !equalPath(index, path) {
+				return
+			}
+			selector := "instance." + embedded.Name + selectorPath(g.fieldType(embedded), path[1:])
+			typ := g.typeString(field.Type())
+			if getter := getPrefix + toCamel(field.Name()); promote.Getter && !exists[getter] {
+				exists[getter] = true
+				accessors.getter(getter, typ)
+				fmt.Fprintf(&g.out, "func (instance *%s) %s() %s {\n", receiver, getter, typ)
+				lock.acquire(&g.out, true)
+				fmt.Fprintf(&g.out, "return %s\n", selector)
+				fmt.Fprintf(&g.out, "}\n")
+			}
+			if setter := setPrefix + toCamel(field.Name()); promote.Setter && !exists[setter] {
+				exists[setter] = true
+				accessors.setter(setter, typ)
+				fmt.Fprintf(&g.out, "func (instance *%s) %s(value %s) {\n", receiver, setter, typ)
+				lock.acquire(&g.out, false)
+				fmt.Fprintf(&g.out, "%s = value\n", selector)
+				if mark := track.mark(embedded.Name); mark != "" {
+					fmt.Fprintf(&g.out, "%s\n", mark)
+				}
+				fmt.Fprintf(&g.out, "}\n")
+			}
+		})
+	}
+}
+
+// walkPromoted calls fn for every field reachable through an embedded field of
+// type typ, path is the index path of typ from the target type. Types already
+// on the path are not walked again to stop at structs embedding themselves
+// through pointers.
+func (g *Generator) walkPromoted(typ types.Type, path []int, seen map[types.Type]bool, fn func(path []int, field *types.Var)) {
+	if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	structType, ok := typ.Underlying().(*types.Struct)
+	if !ok || seen[typ] {
+		return
+	}
+	seen[typ] = true
+	defer delete(seen, typ)
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		fieldPath := append(append(make([]int, 0, len(path)+1), path...), i)
+		fn(fieldPath, field)
+		if field.Embedded() {
+			g.walkPromoted(field.Type(), fieldPath, seen, fn)
+		}
+	}
+}
+
+// selectorPath returns the explicit selectors of the fields at path in typ,
+// e.g. ".Inner.name".
+func selectorPath(typ types.Type, path []int) string {
+	var selector string
+	for _, index := range path {
+		if ptr, ok := typ.Underlying().(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		field := typ.Underlying().(*types.Struct).Field(index)
+		selector += "." + field.Name()
+		typ = field.Type()
+	}
+	return selector
+}
+
+func equalPath(a []int, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
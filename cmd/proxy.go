@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"go/types"
+	"log"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genProxyGetter generates a getter of a field nested in field, which is
+// specified by the proxy option of its getter tag, e.g. `getter:",proxy=String"`,
+// the nested field is read holding lock if it is not nil.
+func (g *Generator) genProxyGetter(receiver string, field *inspect.Field, proxy *proxyCtx, methods map[string]bool, accessors *accessorCtx, lock *lockCtx) {
+	nested := g.lookupProxy(field, proxy)
+	method := proxy.Method
+	if method == "" {
+		method = toCamel(proxy.Field)
+	}
+	g.checkMethod(field, method, methods)
+	typ := g.typeString(nested.Type())
+	accessors.getter(method, refType(proxy.IsRef)+typ)
+	if lock != nil {
+		genLockedFieldGetter(&g.out, receiver, method, field.Name+"."+proxy.Field, typ, proxy.IsRef, lock)
+	} else {
+		genFieldGetter(&g.out, receiver, method, field.Name+"."+proxy.Field, typ, proxy.IsRef)
+	}
+}
+
+// genProxySetter generates a setter of a field nested in field, which is
+// specified by the proxy option of its setter tag, e.g. `setter:",proxy=String"`,
+// the nested field is written holding lock if it is not nil, and the change is
+// recorded as a change of field by track.
+func (g *Generator) genProxySetter(receiver string, field *inspect.Field, proxy *proxyCtx, methods map[string]bool, accessors *accessorCtx, lock *lockCtx, track *trackCtx) {
+	nested := g.lookupProxy(field, proxy)
+	method := proxy.Method
+	if method == "" {
+		method = "Set" + toCamel(proxy.Field)
+	}
+	g.checkMethod(field, method, methods)
+	typ := g.typeString(nested.Type())
+	accessors.setter(method, typ)
+	if lock != nil {
+		genLockedFieldSetter(&g.out, receiver, method, field.Name+"."+proxy.Field, typ, lock, track.mark(field.Name))
+	} else {
+		genFieldSetter(&g.out, receiver, method, field.Name+"."+proxy.Field, typ, track.mark(field.Name))
+	}
+}
+
+// lookupProxy verifies that the proxied field exists in the type of field and
+// is accessible from the generated code, generation is aborted otherwise.
+func (g *Generator) lookupProxy(field *inspect.Field, proxy *proxyCtx) *types.Var {
+	position := g.GetFset().Position(field.Ast.Tag.Pos())
+	typ := g.fieldType(field)
+	obj, index, _ := types.LookupFieldOrMethod(typ, true, g.GetTypes(), proxy.Field)
+	switch nested := obj.(type) {
+	case *types.Var:
+		return nested
+	case *types.Func:
+		log.Fatalf("%s: can not proxy %s.%s: %s is a method, not a field",
+			position, field.Name, proxy.Field, proxy.Field)
+	default:
+		if index != nil {
+			log.Fatalf("%s: can not proxy %s.%s: ambiguous selector", position, field.Name, proxy.Field)
+		}
+		log.Fatalf("%s: can not proxy %s.%s: type %s has no accessible field %s",
+			position, field.Name, proxy.Field, g.typeString(typ), proxy.Field)
+	}
+	return nil
+}
+
+func (g *Generator) checkMethod(field *inspect.Field, method string, methods map[string]bool) {
+	if methods[method] {
+		log.Fatalf("%s: method %s is generated more than once",
+			g.GetFset().Position(field.Ast.Pos()), method)
+	}
+	methods[method] = true
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genSQL generates Columns, ScanRow and Values for t if it has the
+// "visc:sql(table=users)" directive, columns are named by db tags and listed
+// in declaration order, and TableName is generated if table is specified.
+// Fields of embedded structs without a name in db tags are flattened into
+// columns, embedded pointers are not supported since they may be nil.
+// ScanRow and Values hold the lock of "visc:all(lock=mu)", if any.
+func (g *Generator) genSQL(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "sql")
+	if !ok {
+		return
+	}
+	type columnCtx struct {
+		Field  *flatField
+		Atomic bool
+	}
+	cx := make([]*columnCtx, 0, len(t.Fields))
+	for _, field := range g.flattenFields(t, "db") {
+		if len(field.Pointers) > 0 {
+			log.Fatalf("%s: can not scan column %q into field %s through embedded pointer %s, tag it with `db:\"-\"`",
+				g.GetFset().Position(field.Pos), field.Key, field.Selector, field.Pointers[0].Selector)
+		}
+		ctx := &columnCtx{Field: field}
+		if _, isAtomic := atomicValue(field.Type); isAtomic {
+			ctx.Atomic = true
+		} else if containsLock(field.Type) {
+			// locks are never stored in databases
+			continue
+		}
+		cx = append(cx, ctx)
+	}
+	receiver := t.String()
+	if table, found := directive.Lookup("table"); found && table != "" {
+		fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) TableName() string { return %q }", receiver, table)
+	}
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) Columns() []string {\n", receiver)
+	fmt.Fprintf(&g.out, "return []string{")
+	for _, ctx := range cx {
+		fmt.Fprintf(&g.out, "\n%q,", ctx.Field.Key)
+	}
+	fmt.Fprintf(&g.out, "\n}\n")
+	fmt.Fprintf(&g.out, "}\n")
+	var (
+		dest   = make([]string, 0, len(cx))
+		decls  = make([]string, 0, len(cx))
+		values = make([]string, 0, len(cx))
+	)
+	for i, ctx := range cx {
+		if ctx.Atomic {
+			value, _ := atomicValue(ctx.Field.Type)
+			v := fmt.Sprintf("v%d", i+1)
+			dest = append(dest, "&"+v)
+			decls = append(decls, fmt.Sprintf("var %s %s\n", v, g.typeString(value)))
+			values = append(values, "instance."+ctx.Field.Selector+".Load()")
+		} else {
+			dest = append(dest, "&instance."+ctx.Field.Selector)
+			values = append(values, "instance."+ctx.Field.Selector)
+		}
+	}
+	lock := g.allLock(t)
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) ScanRow(scanner interface{ Scan(...any) error }) error {\n", receiver)
+	lock.acquire(&g.out, false)
+	if len(decls) == 0 {
+		fmt.Fprintf(&g.out, "return scanner.Scan(%s)\n", strings.Join(dest, ", "))
+	} else {
+		fmt.Fprintf(&g.out, "%s", strings.Join(decls, ""))
+		fmt.Fprintf(&g.out, "if err := scanner.Scan(%s); err != nil {\nreturn err\n}\n", strings.Join(dest, ", "))
+		for i, ctx := range cx {
+			if ctx.Atomic {
+				fmt.Fprintf(&g.out, "instance.%s.Store(v%d)\n", ctx.Field.Selector, i+1)
+			}
+		}
+		fmt.Fprintf(&g.out, "return nil\n")
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) Values() []any {\n", receiver)
+	lock.acquire(&g.out, true)
+	fmt.Fprintf(&g.out, "return []any{")
+	for _, value := range values {
+		fmt.Fprintf(&g.out, "\n%s,", value)
+	}
+	fmt.Fprintf(&g.out, "\n}\n")
+	fmt.Fprintf(&g.out, "}")
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"log"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// redactedValue replaces the values of redacted fields in String and GoString.
+const redactedValue = "***"
+
+// genStringer generates String and GoString for t if it has the
+// "visc:stringer(redact=password token)" directive, fields are printed in
+// declaration order, and the values of the fields listed in redact or tagged
+// with `visc:"redact"` are masked. Locks and func fields are not printed, and
+// the fields are read holding the lock of "visc:all(lock=mu)", if any.
+func (g *Generator) genStringer(t *inspect.Type) {
+	directive, ok := lookupDirective(docComments(t), "stringer")
+	if !ok {
+		return
+	}
+	redact := make(map[string]bool)
+	if names, found := directive.Lookup("redact"); found {
+		for _, name := range strings.Fields(names) {
+			if !hasField(t, name) {
+				log.Fatalf("%s: redacted field %q is not found in %s",
+					g.GetFset().Position(t.Spec.Pos()), name, t.Spec.Name.String())
+			}
+			redact[name] = true
+		}
+	}
+	fields := make([]*inspect.Field, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		if field.Name == "_" || bookkeeping(t, field) {
+			continue
+		}
+		if _, isAtomic := atomicValue(field.Type); !isAtomic && containsLock(field.Type) {
+			// locks hold nothing to be printed
+			continue
+		}
+		if _, isFunc := field.Type.Underlying().(*types.Signature); isFunc {
+			// func values are not printable
+			continue
+		}
+		for _, option := range strings.Split(structTag(field).Get("visc"), ",") {
+			if strings.TrimSpace(option) == "redact" {
+				redact[field.Name] = true
+			}
+		}
+		fields = append(fields, field)
+	}
+	var (
+		name     = t.Spec.Name.String()
+		receiver = g.valueReceiver(t)
+		lock     = g.allLock(t)
+		str      = make([]string, 0, len(fields))
+		goStr    = make([]string, 0, len(fields))
+		args     = make([]string, 0, len(fields))
+	)
+	for _, field := range fields {
+		if redact[field.Name] {
+			str = append(str, field.Name+": "+redactedValue)
+			goStr = append(goStr, field.Name+":"+redactedValue)
+			continue
+		}
+		str = append(str, field.Name+": %v")
+		goStr = append(goStr, field.Name+":%#v")
+		value := "instance." + field.Name
+		if _, isAtomic := atomicValue(field.Type); isAtomic {
+			value += ".Load()"
+		}
+		args = append(args, value)
+	}
+	for _, method := range []struct {
+		Name   string
+		Type   string
+		Fields []string
+	}{
+		{Name: "String", Type: name, Fields: str},
+		{Name: "GoString", Type: g.GetTypes().Name() + "." + name, Fields: goStr},
+	} {
+		fmt.Fprintf(&g.out, "\n\nfunc (instance %s) %s() string {\n", receiver, method.Name)
+		if strings.HasPrefix(receiver, "*") {
+			fmt.Fprintf(&g.out, "if instance == nil {\nreturn \"<nil>\"\n}\n")
+		}
+		if len(args) > 0 {
+			lock.acquire(&g.out, true)
+		}
+		format := method.Type + "{" + strings.Join(method.Fields, ", ") + "}"
+		if len(args) == 0 {
+			fmt.Fprintf(&g.out, "return %q\n", format)
+		} else {
+			fmt.Fprintf(&g.out, "return %s.Sprintf(%q, %s)\n", g.importPackage("fmt"), format, strings.Join(args, ", "))
+		}
+		fmt.Fprintf(&g.out, "}")
+	}
+}
+
+// hasField reports whether t has a field with the given name.
+func hasField(t *inspect.Type, name string) bool {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package accessors
+
+import (
+	"database/sql"
+	"time"
+)
+
+// visc:all(getter=true, setter=true, setPrefix=Set)
+type Account struct {
+	id    int64 `setter:"-"`
+	owner string
+	nick  sql.NullString `getter:"*,ref" setter:"Rename"`
+}
+
+// visc:construct(name=construct, prefix=Get)
+type User struct {
+	id   int64  `setter:"setId"`
+	name string `setter:"-" construct:"rename(string)"`
+	at   int64  `construct:"setAt(time.Time)"`
+}
+
+func (instance *User) rename(name string) { instance.name = name }
+
+func (instance *User) setAt(at time.Time) { instance.at = at.Unix() }
+
+type Team struct {
+	members []string       `getter:"*,copy"`
+	roles   map[string]int `getter:"*"`
+}
+
+// visc:all(getter=true, copy=true)
+type Grid struct {
+	size  int
+	cells *[2][]int
+}
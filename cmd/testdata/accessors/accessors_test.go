@@ -0,0 +1,42 @@
+package accessors
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAccessors(t *testing.T) {
+	var account Account
+	account.SetOwner("bob")
+	account.Nick().String = "b"
+	if account.Owner() != "bob" || account.nick.String != "b" {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+}
+
+type source struct{}
+
+func (source) GetId() int64     { return 1 }
+func (source) GetName() string  { return "alice" }
+func (source) GetAt() time.Time { return time.Unix(1, 0) }
+
+func TestConstruct(t *testing.T) {
+	user := new(User).construct(source{})
+	if user.id != 1 || user.name != "alice" || user.at != 1 {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	team := Team{members: []string{"a"}, roles: map[string]int{}}
+	team.Members()[0] = "b"
+	team.Roles()["a"] = 1
+	if team.members[0] != "a" || team.roles["a"] != 1 {
+		t.Fatalf("unexpected team: %+v", team)
+	}
+	grid := Grid{size: 2, cells: &[2][]int{{1}, {2}}}
+	grid.Cells()[0][0] = 0
+	if grid.Size() != 2 || grid.cells[0][0] != 1 {
+		t.Fatalf("unexpected grid: %+v", grid)
+	}
+}
@@ -0,0 +1,15 @@
+package atomics
+
+import "sync/atomic"
+
+type Config struct {
+	Name string
+}
+
+// visc:all(getter=true, setter=true, setPrefix=Set)
+type Stats struct {
+	hits   atomic.Int64 `setter:"*,swap,cas"`
+	ready  atomic.Bool
+	config atomic.Pointer[Config]
+	value  atomic.Value `getter:"*,ref" setter:"-"`
+}
@@ -0,0 +1,21 @@
+package atomics
+
+import "testing"
+
+func TestAtomic(t *testing.T) {
+	var stats Stats
+	stats.SetHits(1)
+	if old := stats.SwapHits(2); old != 1 {
+		t.Fatalf("unexpected old hits: %d", old)
+	}
+	if !stats.CompareAndSwapHits(2, 3) || stats.CompareAndSwapHits(2, 4) {
+		t.Fatal("unexpected result of CompareAndSwapHits")
+	}
+	config := &Config{Name: "c"}
+	stats.SetReady(true)
+	stats.SetConfig(config)
+	stats.Value().Store(1)
+	if stats.Hits() != 3 || !stats.Ready() || stats.Config() != config || stats.value.Load() != 1 {
+		t.Fatalf("unexpected stats: %d, %v, %v", stats.Hits(), stats.Ready(), stats.Config())
+	}
+}
@@ -0,0 +1,17 @@
+package builder
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// visc:builder(name=JobBuilder)
+type Job struct {
+	mu       sync.Mutex
+	id       int64  `builder:"required"`
+	name     string `builder:"Title,required"`
+	deadline time.Time
+	retries  atomic.Int32
+	internal bool `builder:"-"`
+}
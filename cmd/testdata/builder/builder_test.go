@@ -0,0 +1,42 @@
+package builder
+
+import "testing"
+
+func TestBuild(t *testing.T) {
+	job, err := NewJobBuilder().Id(1).Title("backup").Retries(3).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.id != 1 || job.name != "backup" || job.retries.Load() != 3 {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+}
+
+func TestBuildRequired(t *testing.T) {
+	_, err := NewJobBuilder().Id(1).Build()
+	if err == nil || err.Error() != "JobBuilder: required fields are not set: name" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestBuildDetached(t *testing.T) {
+	builder := NewJobBuilder().Id(1).Title("backup")
+	job, err := builder.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	builder.Id(2).Retries(1)
+	if job.id != 1 || job.retries.Load() != 0 {
+		t.Fatalf("built job is changed by the builder: %+v", job)
+	}
+	if _, err = builder.Build(); err == nil {
+		t.Fatal("required fields of the reset builder are not checked")
+	}
+	next, err := builder.Title("restore").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next == job || next.id != 2 || next.name != "restore" {
+		t.Fatalf("unexpected job: %+v", next)
+	}
+}
@@ -0,0 +1,31 @@
+package clone
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+type Item struct {
+	name  string
+	attrs map[string][]string
+}
+
+type Slot struct {
+	mu    sync.Mutex
+	label string
+	hits  atomic.Int64
+}
+
+// visc:clone
+type Order struct {
+	mu     sync.Mutex
+	items  []*Item
+	counts [3]int
+	leader *Item
+	file   *os.File
+	hits   atomic.Int64
+	notify func()
+	slots  [2]Slot
+	locks  [2]sync.Mutex
+}
@@ -0,0 +1,33 @@
+package clone
+
+import (
+	"os"
+	"testing"
+)
+
+func TestClone(t *testing.T) {
+	leader := &Item{name: "a", attrs: map[string][]string{"k": {"v"}}}
+	order := &Order{
+		items:  []*Item{leader},
+		counts: [3]int{1, 2, 3},
+		leader: leader,
+		file:   os.Stdout,
+	}
+	order.hits.Store(7)
+	order.slots[1].label = "s"
+	order.slots[1].hits.Store(3)
+	clone := order.Clone()
+	clone.items[0].name = "b"
+	clone.items[0].attrs["k"][0] = "w"
+	clone.leader.attrs["x"] = nil
+	clone.counts[0] = 0
+	if leader.name != "a" || leader.attrs["k"][0] != "v" || len(leader.attrs) != 1 || order.counts[0] != 1 {
+		t.Fatalf("original is modified: %+v %+v", order, leader)
+	}
+	if clone.items[0] == leader || clone.leader == leader {
+		t.Fatal("pointers are not cloned")
+	}
+	if clone.file != os.Stdout || clone.hits.Load() != 7 || clone.slots[1].label != "s" || clone.slots[1].hits.Load() != 3 {
+		t.Fatalf("unexpected clone: %+v", clone)
+	}
+}
@@ -0,0 +1,11 @@
+package collection
+
+type Cart struct {
+	items   []string          `collection:"*"`
+	attrs   map[string]string `collection:"*"`
+	entries []int             `collection:"*"`
+	codes   map[int]bool      `collection:"Coupons"`
+	states  []string          `collection:"Statuses"`
+	status  map[int]string    `collection:"*"`
+	data    []byte            `collection:"*,item=Datum"`
+}
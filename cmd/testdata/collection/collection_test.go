@@ -0,0 +1,54 @@
+package collection
+
+import "testing"
+
+func TestSlice(t *testing.T) {
+	var cart Cart
+	cart.AppendItems("a", "b")
+	cart.AppendEntries(1)
+	if cart.LenItems() != 2 || cart.ItemAt(1) != "b" || cart.EntryAt(0) != 1 {
+		t.Fatalf("unexpected cart: %+v", cart)
+	}
+	var visited []string
+	cart.RangeItems(func(index int, value string) bool {
+		visited = append(visited, value)
+		return false
+	})
+	if len(visited) != 1 || visited[0] != "a" {
+		t.Fatalf("unexpected visited items: %v", visited)
+	}
+}
+
+func TestMap(t *testing.T) {
+	var cart Cart
+	cart.PutAttr("color", "red")
+	cart.PutCoupon(7, true)
+	if value, ok := cart.GetAttr("color"); !ok || value != "red" {
+		t.Fatalf("unexpected attr: %q, %v", value, ok)
+	}
+	cart.DeleteAttr("color")
+	if _, ok := cart.GetAttr("color"); ok {
+		t.Fatal("attr is not deleted")
+	}
+	count := 0
+	cart.RangeCoupons(func(key int, value bool) bool {
+		count++
+		return true
+	})
+	if count != 1 {
+		t.Fatalf("unexpected coupon count: %d", count)
+	}
+}
+
+func TestSingular(t *testing.T) {
+	var cart Cart
+	cart.AppendStatuses("paid")
+	cart.PutStatus(1, "shipped")
+	cart.AppendData('x')
+	if cart.StatusAt(0) != "paid" || cart.DatumAt(0) != 'x' {
+		t.Fatalf("unexpected cart: %+v", cart)
+	}
+	if value, ok := cart.GetStatus(1); !ok || value != "shipped" {
+		t.Fatalf("unexpected status: %q, %v", value, ok)
+	}
+}
@@ -0,0 +1,38 @@
+package embedded
+
+import "time"
+
+type Base struct {
+	id      int64
+	created time.Time
+}
+
+type audit struct {
+	by string
+	id string
+}
+
+type Model struct {
+	*Base `getter:"*,promote" setter:",promote"`
+	audit `getter:"*,promote"`
+	name  string `getter:"*"`
+}
+
+type Tag struct {
+	label string
+}
+
+type Labeled struct {
+	Tag
+}
+
+// Entry embeds Tag both directly and through Labeled, and the shallower one
+// is promoted.
+type Entry struct {
+	Labeled
+	Tag
+}
+
+type Item struct {
+	Entry `getter:"*,promote" setter:"*,promote"`
+}
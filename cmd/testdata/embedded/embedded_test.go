@@ -0,0 +1,23 @@
+package embedded
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPromote(t *testing.T) {
+	model := Model{Base: new(Base), audit: audit{by: "root"}}
+	now := time.Now()
+	model.SetCreated(now)
+	if model.GetBase() != model.Base || !model.Created().Equal(now) || model.By() != "root" {
+		t.Fatalf("unexpected model: %+v", model)
+	}
+}
+
+func TestPromoteShallower(t *testing.T) {
+	var item Item
+	item.SetLabel("new")
+	if item.Label() != "new" || item.Tag().label != "new" || item.Labeled().label != "" {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+}
@@ -0,0 +1,29 @@
+package equal
+
+import (
+	"sync"
+	"time"
+)
+
+type Inner struct {
+	mu   sync.Mutex
+	name string
+}
+
+// visc:equal
+type Event struct {
+	mu      sync.Mutex
+	id      int64
+	at      time.Time
+	tags    []string
+	attrs   map[string]int
+	data    []byte
+	parent  *Event
+	meta    any
+	inner   Inner
+	pair    [2]Inner
+	named   map[string]*Inner
+	byName  map[string]Inner
+	hook    func()
+	ignored string `equal:"-"`
+}
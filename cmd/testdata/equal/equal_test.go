@@ -0,0 +1,59 @@
+package equal
+
+import (
+	"testing"
+	"time"
+)
+
+func newEvent() *Event {
+	return &Event{
+		id:     1,
+		at:     time.Unix(1, 0),
+		tags:   []string{"a"},
+		attrs:  map[string]int{"k": 1},
+		data:   []byte("d"),
+		parent: &Event{id: 2},
+		meta:   []int{1},
+		inner:  Inner{name: "i"},
+		pair:   [2]Inner{{name: "p"}},
+		named:  map[string]*Inner{"n": {name: "n"}},
+		byName: map[string]Inner{"b": {name: "b"}},
+		hook:   func() {},
+	}
+}
+
+func TestEqual(t *testing.T) {
+	a, b := newEvent(), newEvent()
+	b.ignored = "x"
+	if !a.Equal(b) {
+		t.Fatal("equal events are reported as different")
+	}
+	if !(*Event)(nil).Equal(nil) || a.Equal(nil) {
+		t.Fatal("unexpected result of comparing nil")
+	}
+}
+
+func TestNotEqual(t *testing.T) {
+	changes := map[string]func(*Event){
+		"id":     func(e *Event) { e.id = 3 },
+		"at":     func(e *Event) { e.at = e.at.Add(time.Second) },
+		"tags":   func(e *Event) { e.tags[0] = "b" },
+		"attrs":  func(e *Event) { e.attrs["k"] = 2 },
+		"keys":   func(e *Event) { e.attrs = map[string]int{"j": 1} },
+		"data":   func(e *Event) { e.data = nil },
+		"parent": func(e *Event) { e.parent.id = 3 },
+		"meta":   func(e *Event) { e.meta = []int{2} },
+		"inner":  func(e *Event) { e.inner.name = "j" },
+		"pair":   func(e *Event) { e.pair[1].name = "q" },
+		"named":  func(e *Event) { e.named["n"].name = "m" },
+		"byName": func(e *Event) { e.byName = map[string]Inner{"b": {name: "c"}} },
+		"hook":   func(e *Event) { e.hook = nil },
+	}
+	for name, change := range changes {
+		a, b := newEvent(), newEvent()
+		change(b)
+		if a.Equal(b) || b.Equal(a) {
+			t.Errorf("events with different %s are reported as equal", name)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package fields
+
+// visc:fields(tag=db)
+type User struct {
+	id   int64  `db:"id"`
+	name string `db:"user_name"`
+	tmp  bool   `db:"-"`
+}
+
+// visc:fields
+type Point struct {
+	x, y int
+}
+
+type Base struct {
+	ID      int64 `db:"id"`
+	Created int64 `db:"created_at"`
+}
+
+// visc:fields(tag=db)
+type Order struct {
+	Base
+	Meta  Base   `db:"meta"`
+	total int64  `db:"total"`
+	note  string `db:"-"`
+}
@@ -0,0 +1,25 @@
+package fields
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFields(t *testing.T) {
+	if fields := UserFields(); !reflect.DeepEqual(fields, []string{"id", "user_name"}) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	if UserFieldName != "user_name" || PointFieldY != "y" {
+		t.Fatal("unexpected field constants")
+	}
+}
+
+func TestFieldsEmbedded(t *testing.T) {
+	expected := []string{"id", "created_at", "meta", "total"}
+	if fields := OrderFields(); !reflect.DeepEqual(fields, expected) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	if OrderFieldCreated != "created_at" {
+		t.Fatal("unexpected field constants")
+	}
+}
@@ -0,0 +1,18 @@
+package iface
+
+import "database/sql"
+
+// visc:interface(name=UserReader, getters=true, setters=false)
+// visc:interface(name=UserWriter, getters=false)
+type User struct {
+	id   int64          `getter:"*"`
+	name string         `getter:"*" setter:"*"`
+	nick sql.NullString `getter:"*,ref"`
+}
+
+// visc:all(setter=true, setPrefix=set)
+// visc:construct(name=construct)
+type Profile struct {
+	id   int64
+	name string
+}
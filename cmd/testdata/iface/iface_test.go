@@ -0,0 +1,18 @@
+package iface
+
+import "testing"
+
+var (
+	_ UserReader = (*User)(nil)
+	_ UserWriter = (*User)(nil)
+)
+
+func TestConstruct(t *testing.T) {
+	user := &User{id: 1}
+	var writer UserWriter = user
+	writer.SetName("bob")
+	profile := new(Profile).construct(user)
+	if profile.id != 1 || profile.name != "bob" {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+}
@@ -0,0 +1,3 @@
+package uuid
+
+type UUID string
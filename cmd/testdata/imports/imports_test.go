@@ -0,0 +1,11 @@
+package imports
+
+import "testing"
+
+func TestImports(t *testing.T) {
+	user := User{id: "u"}
+	user.SetOrder(&Order{id: [16]byte{1}})
+	if user.Id() != "u" || user.Order().Id()[0] != 1 {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
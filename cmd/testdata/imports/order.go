@@ -0,0 +1,7 @@
+package imports
+
+import "github.com/x5iu/visc/cmd/testdata/imports/a/uuid"
+
+type Order struct {
+	id uuid.UUID `getter:"*"`
+}
@@ -0,0 +1,8 @@
+package imports
+
+import "github.com/x5iu/visc/cmd/testdata/imports/b/uuid"
+
+type User struct {
+	id    uuid.UUID `getter:"*"`
+	order *Order    `getter:"*" setter:"*"`
+}
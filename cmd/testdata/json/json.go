@@ -0,0 +1,28 @@
+package json
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Base struct {
+	id      int64 `json:"id,string"`
+	created time.Time
+}
+
+type Extra struct {
+	Note string `json:"note"`
+}
+
+// visc:json
+type User struct {
+	Base
+	*Extra
+	mu     sync.Mutex
+	name   string   `json:"name"`
+	email  string   `json:"email,omitempty"`
+	tags   []string `json:"tags,omitempty"`
+	hits   atomic.Int32
+	secret string `json:"-"`
+}
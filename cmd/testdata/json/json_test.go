@@ -0,0 +1,65 @@
+package json
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestMarshal(t *testing.T) {
+	user := User{Base: Base{id: 1, created: time.Unix(0, 0).UTC()}, name: "bob", secret: "s"}
+	user.hits.Store(2)
+	data, err := json.Marshal(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"id":"1","created":"1970-01-01T00:00:00Z","name":"bob","hits":2}`
+	if string(data) != expected {
+		t.Fatalf("unexpected json: %s", data)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	user := User{
+		Base:   Base{id: 1, created: time.Unix(1, 0).UTC()},
+		Extra:  &Extra{Note: "n"},
+		name:   "bob",
+		email:  "b@x",
+		tags:   []string{"a"},
+		secret: "s",
+	}
+	user.hits.Store(3)
+	data, err := json.Marshal(&user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded User
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.id != 1 || !decoded.created.Equal(user.created) || decoded.Extra == nil || decoded.Note != "n" ||
+		decoded.name != "bob" || decoded.email != "b@x" || len(decoded.tags) != 1 || decoded.hits.Load() != 3 ||
+		decoded.secret != "" {
+		t.Fatalf("unexpected user: %s", data)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	var user User
+	if err := json.Unmarshal([]byte(`{"id":1}`), &user); err == nil {
+		t.Fatal("a number is accepted by the string option")
+	}
+	if err := json.Unmarshal([]byte(`{"name":1}`), &user); err == nil {
+		t.Fatal("a number is accepted by a string field")
+	}
+	if err := json.Unmarshal([]byte(`{"unknown":1,"Name":"x"}`), &user); err != nil || user.name != "" {
+		t.Fatalf("unexpected result of unknown keys: %v, %q", err, user.name)
+	}
+}
+
+func TestUnmarshalNull(t *testing.T) {
+	user := User{Base: Base{id: 1}}
+	if err := json.Unmarshal([]byte(`{"id":null}`), &user); err != nil || user.id != 1 {
+		t.Fatalf("unexpected result of null: %v, %d", err, user.id)
+	}
+}
@@ -0,0 +1,44 @@
+package lock
+
+import "sync"
+
+// visc:all(getter=true, setter=true, setPrefix=Set, lock=mu)
+type Cache struct {
+	mu   sync.RWMutex
+	hits int
+	name string `getter:"*,lock=guard" setter:"-"`
+
+	guard *sync.Mutex `getter:"-" setter:"-"`
+}
+
+type Meta struct {
+	Label string
+}
+
+type Inner struct {
+	Name string
+}
+
+// visc:all(getter=true, setter=true, setPrefix=Set, lock=mu)
+type Registry struct {
+	Meta  `getter:",promote" setter:",promote"`
+	mu    sync.RWMutex
+	tags  []string       `getter:"-" setter:"-" collection:"*"`
+	attrs map[string]int `getter:"-" setter:"-" collection:"*"`
+	inner Inner          `getter:",proxy=Name" setter:",proxy=Name"`
+}
+
+// visc:all(getter=true, setter=true, setPrefix=Set, lock=mu)
+// visc:clone
+// visc:equal
+// visc:stringer
+// visc:json
+// visc:sql
+// visc:map
+// visc:validate
+type Account struct {
+	mu      sync.RWMutex
+	owner   string `validate:"required"`
+	balance int64
+	labels  []string
+}
@@ -0,0 +1,163 @@
+package lock
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	cache := Cache{guard: new(sync.Mutex), name: "c"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				cache.SetHits(cache.Hits() + 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if cache.Hits() == 0 || cache.Name() != "c" {
+		t.Fatalf("unexpected cache: hits=%d, name=%s", cache.Hits(), cache.Name())
+	}
+	if !cache.mu.TryLock() || !cache.guard.TryLock() {
+		t.Fatal("locks are not released")
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	var registry Registry
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			registry.AppendTags("t")
+			registry.PutAttr("a", registry.LenTags())
+			registry.SetName("n")
+			registry.SetLabel(registry.Name())
+		}()
+	}
+	wg.Wait()
+	if registry.LenTags() != 8 || registry.Label() != "n" {
+		t.Fatalf("unexpected registry: tags=%d, label=%s", registry.LenTags(), registry.Label())
+	}
+	if _, ok := registry.GetAttr("a"); !ok || !registry.mu.TryLock() {
+		t.Fatal("unexpected registry")
+	}
+}
+
+// race runs fn concurrently with setters of account, the data race is
+// reported by go test -race if fn reads or writes the fields without the lock.
+func race(t *testing.T, account *Account, fn func()) {
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			account.SetBalance(int64(i))
+			account.SetOwner("owner")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			fn()
+		}
+	}()
+	wg.Wait()
+	if !account.mu.TryLock() {
+		t.Fatal("lock is not released")
+	}
+	account.mu.Unlock()
+}
+
+func TestAccountClone(t *testing.T) {
+	account := &Account{labels: []string{"a"}}
+	race(t, account, func() {
+		if clone := account.Clone(); len(clone.Labels()) != 1 {
+			t.Errorf("unexpected clone: %+v", clone)
+		}
+	})
+}
+
+func TestAccountEqual(t *testing.T) {
+	account, other := &Account{}, &Account{}
+	race(t, account, func() {
+		account.Equal(other)
+		other.Equal(account)
+		if !account.Equal(account) {
+			t.Error("account is not equal to itself")
+		}
+	})
+}
+
+func TestAccountString(t *testing.T) {
+	account := &Account{}
+	race(t, account, func() {
+		if s := account.String(); !strings.HasPrefix(s, "Account{") {
+			t.Errorf("unexpected string: %s", s)
+		}
+		_ = account.GoString()
+	})
+}
+
+func TestAccountJSON(t *testing.T) {
+	account := &Account{}
+	race(t, account, func() {
+		data, err := json.Marshal(account)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if err := json.Unmarshal(data, account); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+type row []any
+
+func (r row) Scan(dest ...any) error {
+	for i, value := range r {
+		switch d := dest[i].(type) {
+		case *string:
+			*d = value.(string)
+		case *int64:
+			*d = value.(int64)
+		case *[]string:
+			*d = value.([]string)
+		}
+	}
+	return nil
+}
+
+func TestAccountSQL(t *testing.T) {
+	account := &Account{}
+	race(t, account, func() {
+		if err := account.ScanRow(row(account.Values())); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestAccountMap(t *testing.T) {
+	account := &Account{}
+	race(t, account, func() {
+		if err := account.FromMap(account.ToMap()); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestAccountValidate(t *testing.T) {
+	account := &Account{owner: "owner"}
+	race(t, account, func() {
+		if err := account.Validate(); err != nil {
+			t.Error(err)
+		}
+	})
+}
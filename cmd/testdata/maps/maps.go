@@ -0,0 +1,32 @@
+package maps
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// visc:map(tag=json)
+type User struct {
+	mu   sync.Mutex
+	id   int64    `json:"id"`
+	name string   `json:"name"`
+	tags []string `json:"tags"`
+	hits atomic.Int64
+	tmp  bool `json:"-"`
+}
+
+type Base struct {
+	ID int64 `json:"id"`
+}
+
+type Audit struct {
+	By   string   `json:"by"`
+	Tags []string `json:"tags"`
+}
+
+// visc:map(tag=json)
+type Order struct {
+	Base
+	*Audit
+	total int64 `json:"total"`
+}
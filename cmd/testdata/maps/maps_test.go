@@ -0,0 +1,53 @@
+package maps
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToMap(t *testing.T) {
+	user := User{id: 1, name: "bob", tags: []string{"a"}, tmp: true}
+	user.hits.Store(2)
+	expected := map[string]any{"id": int64(1), "name": "bob", "tags": []string{"a"}, "hits": int64(2)}
+	if m := user.ToMap(); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+}
+
+func TestFromMap(t *testing.T) {
+	user := User{name: "bob"}
+	if err := user.FromMap(map[string]any{"id": int64(1), "tags": nil, "hits": int64(2)}); err != nil {
+		t.Fatal(err)
+	}
+	if user.id != 1 || user.name != "bob" || user.tags != nil || user.hits.Load() != 2 {
+		t.Fatalf("unexpected user: id=%d, name=%s", user.id, user.name)
+	}
+	err := user.FromMap(map[string]any{"id": "1"})
+	if err == nil || err.Error() != "User: field id: expected int64, got string" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestMapEmbedded(t *testing.T) {
+	order := Order{Base: Base{ID: 1}, total: 2}
+	if m := order.ToMap(); !reflect.DeepEqual(m, map[string]any{"id": int64(1), "total": int64(2)}) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	if err := order.FromMap(map[string]any{"tags": nil}); err != nil || order.Audit != nil {
+		t.Fatalf("nil embedded pointer is allocated: %v", err)
+	}
+	if err := order.FromMap(map[string]any{"id": int64(3), "by": "bob"}); err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 3 || order.Audit == nil || order.By != "bob" {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+	expected := map[string]any{"id": int64(3), "by": "bob", "tags": []string(nil), "total": int64(2)}
+	if m := order.ToMap(); !reflect.DeepEqual(m, expected) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	err := order.FromMap(map[string]any{"by": 1})
+	if err == nil || err.Error() != "Order: field Audit.By: expected string, got int" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
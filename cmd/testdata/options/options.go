@@ -0,0 +1,18 @@
+package options
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// visc:options(name=Option, prefix=With)
+// visc:track
+type Server struct {
+	mu      sync.Mutex
+	changes uint8
+	addr    string
+	timeout time.Duration `option:"WithDeadline"`
+	conns   atomic.Int32
+	secret  string `option:"-"`
+}
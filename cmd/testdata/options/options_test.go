@@ -0,0 +1,13 @@
+package options
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOptions(t *testing.T) {
+	server := NewServer(WithAddr(":8080"), WithDeadline(time.Second), WithConns(3))
+	if server.addr != ":8080" || server.timeout != time.Second || server.conns.Load() != 3 || server.secret != "" {
+		t.Fatalf("unexpected server: %+v", server)
+	}
+}
@@ -0,0 +1,27 @@
+package patch
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// visc:patch
+type Config struct {
+	name   string    `json:"name"`
+	port   int       `json:"port"`
+	tags   []string  `json:"tags"`
+	expire time.Time `json:"expire"`
+	hits   atomic.Int64
+	secret string `patch:"-"`
+}
+
+// visc:all(getter=true, lock=mu)
+// visc:track(field=changes)
+// visc:patch
+type Session struct {
+	mu      sync.RWMutex
+	user    string
+	hits    atomic.Int64
+	changes uint8
+}
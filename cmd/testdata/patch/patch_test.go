@@ -0,0 +1,55 @@
+package patch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApply(t *testing.T) {
+	config := Config{name: "a", port: 80, secret: "s"}
+	var patch ConfigPatch
+	if err := json.Unmarshal([]byte(`{"port":8080,"tags":["x"]}`), &patch); err != nil {
+		t.Fatal(err)
+	}
+	config.Apply(patch)
+	if config.name != "a" || config.port != 8080 || len(config.tags) != 1 || config.secret != "s" {
+		t.Fatalf("unexpected config: %+v", &config)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := &Config{name: "a", tags: []string{"x"}, expire: time.Unix(1, 0)}
+	b := &Config{name: "a", tags: []string{"y"}, expire: time.Unix(1, 0).UTC(), secret: "s"}
+	b.hits.Store(1)
+	patch := DiffConfig(a, b)
+	if patch.Name != nil || patch.Port != nil || patch.Expire != nil || patch.Tags == nil || patch.Hits == nil {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+	a.Apply(patch)
+	if a.tags[0] != "y" || a.hits.Load() != 1 || DiffConfig(a, b) != (ConfigPatch{}) {
+		t.Fatalf("unexpected config: %+v", a)
+	}
+}
+
+func TestApplyTracked(t *testing.T) {
+	var session Session
+	user := "u"
+	session.Apply(SessionPatch{User: &user})
+	if changed := session.Changed(); session.User() != "u" || !reflect.DeepEqual(changed, []string{"user"}) {
+		t.Fatalf("unexpected session: %+v", &session)
+	}
+}
+
+func TestDiffLocked(t *testing.T) {
+	a, b := &Session{user: "a"}, &Session{user: "b"}
+	b.hits.Store(1)
+	if patch := DiffSession(a, a); patch != (SessionPatch{}) {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+	patch := DiffSession(a, b)
+	if patch.User == nil || *patch.User != "b" || patch.Hits == nil || *patch.Hits != 1 {
+		t.Fatalf("unexpected patch: %+v", patch)
+	}
+}
@@ -0,0 +1,17 @@
+package proxy
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Inner struct {
+	Name string
+	Age  int
+}
+
+type User struct {
+	nick  sql.NullString `getter:",proxy=String Valid:HasNick" setter:",proxy=String:Rename"`
+	inner *Inner         `getter:",proxy=*Name Age:Years" setter:",proxy=Name"`
+	at    time.Time      `getter:"*"`
+}
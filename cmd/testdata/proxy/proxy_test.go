@@ -0,0 +1,13 @@
+package proxy
+
+import "testing"
+
+func TestProxy(t *testing.T) {
+	user := User{inner: &Inner{Age: 3}}
+	user.Rename("bob")
+	user.SetName("alice")
+	*user.Name() += "!"
+	if user.String() != "bob" || user.HasNick() || user.Years() != 3 || user.inner.Name != "alice!" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+}
@@ -0,0 +1,22 @@
+package sql
+
+import (
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Model struct {
+	id      int64     `db:"id"`
+	created time.Time `db:"created_at"`
+}
+
+// visc:sql(table=users)
+type User struct {
+	Model
+	mu    sync.Mutex
+	name  sql.NullString `db:"name"`
+	hits  atomic.Int64   `db:"hits"`
+	cache []byte         `db:"-"`
+}
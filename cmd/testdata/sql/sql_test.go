@@ -0,0 +1,35 @@
+package sql
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// row scans values in order like *sql.Row does.
+type row []any
+
+func (r row) Scan(dest ...any) error {
+	for i, value := range r {
+		reflect.ValueOf(dest[i]).Elem().Set(reflect.ValueOf(value))
+	}
+	return nil
+}
+
+func TestSQL(t *testing.T) {
+	var user User
+	if user.TableName() != "users" {
+		t.Fatalf("unexpected table name: %s", user.TableName())
+	}
+	if columns := user.Columns(); !reflect.DeepEqual(columns, []string{"id", "created_at", "name", "hits"}) {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	values := row{int64(1), time.Unix(1, 0), sql.NullString{String: "bob", Valid: true}, int64(2)}
+	if err := user.ScanRow(values); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(row(user.Values()), values) {
+		t.Fatalf("unexpected values: %v", user.Values())
+	}
+}
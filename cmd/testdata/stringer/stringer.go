@@ -0,0 +1,19 @@
+package stringer
+
+import "sync"
+
+// visc:stringer(redact=password token)
+type User struct {
+	name     string
+	age      int
+	password string
+	token    string
+	secret   string `visc:"redact"`
+	hook     func()
+}
+
+// visc:stringer
+type Cache struct {
+	mu   sync.Mutex
+	hits int
+}
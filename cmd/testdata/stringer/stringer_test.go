@@ -0,0 +1,19 @@
+package stringer
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStringer(t *testing.T) {
+	user := User{name: "bob", age: 18, password: "p", token: "t", secret: "s", hook: func() {}}
+	if s := fmt.Sprint(user); s != "User{name: bob, age: 18, password: ***, token: ***, secret: ***}" {
+		t.Fatalf("unexpected String: %s", s)
+	}
+	if s := fmt.Sprintf("%#v", &user); s != `stringer.User{name:"bob", age:18, password:***, token:***, secret:***}` {
+		t.Fatalf("unexpected GoString: %s", s)
+	}
+	if s := fmt.Sprint(&Cache{hits: 1}); s != "Cache{hits: 1}" {
+		t.Fatalf("unexpected String: %s", s)
+	}
+}
@@ -0,0 +1,44 @@
+package track
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type Meta struct {
+	Label string
+}
+
+type Inner struct {
+	Name string
+}
+
+// visc:all(getter=true, setter=true, setPrefix=Set, lock=mu)
+// visc:track(field=changes)
+type User struct {
+	Meta    `getter:"-" setter:"-,promote"`
+	mu      sync.Mutex
+	changes uint8
+	name    string
+	age     int
+	email   string       `setter:"-"`
+	hits    atomic.Int64 `setter:"*,swap,cas"`
+	tags    []string     `setter:"-" collection:"*"`
+	inner   Inner        `getter:"-" setter:"-,proxy=Name:Rename"`
+}
+
+// visc:all(setter=true, setPrefix=Set)
+// visc:track
+// visc:equal
+// visc:stringer
+// visc:json
+// visc:sql
+// visc:map(tag=json)
+// visc:patch
+// visc:visit
+// visc:fields(tag=json)
+type Record struct {
+	changes uint8
+	id      int64  `json:"id" db:"id"`
+	name    string `json:"name" db:"name"`
+}
@@ -0,0 +1,71 @@
+package track
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrack(t *testing.T) {
+	var user User
+	user.SetAge(1)
+	user.SetName("bob")
+	if changed := user.Changed(); !reflect.DeepEqual(changed, []string{"name", "age"}) {
+		t.Fatalf("unexpected changed fields: %v", changed)
+	}
+	if !user.IsChanged("age") || user.IsChanged("email") || user.IsChanged("changes") {
+		t.Fatal("unexpected result of IsChanged")
+	}
+	user.ResetChanges()
+	if changed := user.Changed(); len(changed) != 0 {
+		t.Fatalf("changes are not reset: %v", changed)
+	}
+}
+
+func TestTrackAll(t *testing.T) {
+	var user User
+	user.SetLabel("l")
+	user.CompareAndSwapHits(0, 1)
+	user.AppendTags("a")
+	user.Rename("n")
+	expected := []string{"Meta", "hits", "tags", "inner"}
+	if changed := user.Changed(); !reflect.DeepEqual(changed, expected) {
+		t.Fatalf("unexpected changed fields: %v", changed)
+	}
+	if !user.mu.TryLock() {
+		t.Fatal("lock is not released")
+	}
+}
+
+func TestBookkeeping(t *testing.T) {
+	var record Record
+	record.SetName("r")
+	if !record.Equal(&Record{name: "r"}) {
+		t.Fatal("records differing only in changes are reported as different")
+	}
+	if s := record.String(); s != "Record{id: 0, name: r}" {
+		t.Fatalf("unexpected String: %s", s)
+	}
+	if data, err := record.MarshalJSON(); err != nil || string(data) != `{"id":0,"name":"r"}` {
+		t.Fatalf("unexpected json: %s, %v", data, err)
+	}
+	if err := record.UnmarshalJSON([]byte(`{"changes":0}`)); err != nil || !record.IsChanged("name") {
+		t.Fatalf("changes are modified by UnmarshalJSON: %v", err)
+	}
+	if columns := record.Columns(); !reflect.DeepEqual(columns, []string{"id", "name"}) {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+	if m := record.ToMap(); !reflect.DeepEqual(m, map[string]any{"id": int64(0), "name": "r"}) {
+		t.Fatalf("unexpected map: %v", m)
+	}
+	if n := reflect.TypeOf(RecordPatch{}).NumField(); n != 2 {
+		t.Fatalf("unexpected number of patch fields: %d", n)
+	}
+	var visited []string
+	record.VisitFieldPointers(func(name string, ptr any) bool {
+		visited = append(visited, name)
+		return true
+	})
+	if !reflect.DeepEqual(visited, []string{"id", "name"}) || !reflect.DeepEqual(RecordFields(), visited) {
+		t.Fatalf("unexpected visited fields: %v", visited)
+	}
+}
@@ -0,0 +1,19 @@
+package validate
+
+type Status string
+
+// visc:validate
+type User struct {
+	name   string   `validate:"required,min=1,max=64,regexp=^[a-z]+$"`
+	role   string   `validate:"oneof=admin user guest"`
+	age    int      `validate:"min=18"`
+	tags   []string `validate:"max=3"`
+	nick   *string  `validate:"required,min=2"`
+	status Status   `validate:"oneof=active inactive"`
+	level  int      `validate:"oneof=1 2 3"`
+}
+
+// Other is left to other validators without visc:validate.
+type Other struct {
+	Email string `validate:"required,email"`
+}
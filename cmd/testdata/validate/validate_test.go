@@ -0,0 +1,35 @@
+package validate
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	nick := "bo"
+	user := User{name: "bob", role: "admin", age: 18, nick: &nick, status: "active", level: 1}
+	if err := user.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateErrors(t *testing.T) {
+	nick := "b"
+	user := User{
+		name:   "Bob",
+		role:   "root",
+		age:    17,
+		tags:   []string{"a", "b", "c", "d"},
+		nick:   &nick,
+		status: "deleted",
+		level:  4,
+	}
+	expected := "User: invalid fields: name must match ^[a-z]+$; role must be one of admin, user, guest; " +
+		"age must be at least 18; tags length must be at most 3; nick length must be at least 2; " +
+		"status must be one of active, inactive; level must be one of 1, 2, 3"
+	if err := user.Validate(); err == nil || err.Error() != expected {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := (&User{role: "user", age: 18, status: "active", level: 2}).Validate(); err == nil ||
+		err.Error() != "User: invalid fields: name is required; name length must be at least 1; "+
+			"name must match ^[a-z]+$; nick is required" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,23 @@
+package visit
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// visc:visit
+type User struct {
+	mu   sync.Mutex
+	id   int64  `json:"id"`
+	name string `json:"name" db:"user_name"`
+	tags []string
+	hits atomic.Int64
+}
+
+// visc:all(getter=true, setter=true, setPrefix=Set, lock=mu)
+// visc:visit
+type Counter struct {
+	mu    sync.RWMutex
+	name  string
+	count int
+}
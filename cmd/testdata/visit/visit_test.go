@@ -0,0 +1,73 @@
+package visit
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestVisitFields(t *testing.T) {
+	user := User{id: 1, name: "bob"}
+	user.hits.Store(2)
+	visited := map[string]any{}
+	user.VisitFields(func(name string, value any) bool {
+		visited[name] = value
+		return true
+	})
+	expected := map[string]any{"id": int64(1), "name": "bob", "tags": []string(nil), "hits": int64(2)}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("unexpected visited fields: %v", visited)
+	}
+	count := 0
+	user.VisitFields(func(string, any) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("visiting is not stopped: %d", count)
+	}
+}
+
+func TestVisitFieldPointers(t *testing.T) {
+	var user User
+	user.VisitFieldPointers(func(name string, ptr any) bool {
+		if name == "name" {
+			*ptr.(*string) = "alice"
+		}
+		return true
+	})
+	if user.name != "alice" {
+		t.Fatalf("unexpected name: %q", user.name)
+	}
+	if tag := user.FieldTag("name"); tag.Get("db") != "user_name" {
+		t.Fatalf("unexpected tag: %q", tag)
+	}
+}
+
+func TestVisitLocked(t *testing.T) {
+	var counter Counter
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			counter.SetCount(i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			// the visitor may call the accessors guarded by the same lock
+			counter.VisitFields(func(name string, value any) bool {
+				if name == "count" {
+					counter.SetName(counter.Name())
+				}
+				return true
+			})
+		}
+	}()
+	wg.Wait()
+	if !counter.mu.TryLock() {
+		t.Fatal("lock is not released")
+	}
+}
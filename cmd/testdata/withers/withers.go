@@ -0,0 +1,21 @@
+package withers
+
+import "sync"
+
+// visc:all(getter=true, withers=true)
+type Money[T any] struct {
+	amount   int64
+	currency string `wither:"In"`
+	extra    T      `getter:"-" wither:"-"`
+}
+
+type Point struct {
+	x int `wither:"*"`
+	y int `wither:"*"`
+}
+
+// visc:all(getter=true, withers=true, lock=mu)
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
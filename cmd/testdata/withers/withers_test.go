@@ -0,0 +1,17 @@
+package withers
+
+import "testing"
+
+func TestWithers(t *testing.T) {
+	price := Money[bool]{amount: 100, currency: "USD"}
+	discount := price.WithAmount(80).In("EUR")
+	if price.Amount() != 100 || price.Currency() != "USD" {
+		t.Fatalf("original is modified: %+v", price)
+	}
+	if discount.Amount() != 80 || discount.Currency() != "EUR" {
+		t.Fatalf("unexpected copy: %+v", discount)
+	}
+	if p := (Point{}).WithX(1).WithY(2); p.x != 1 || p.y != 2 {
+		t.Fatalf("unexpected point: %+v", p)
+	}
+}
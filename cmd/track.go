@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"log"
+	"sort"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// trackCtx is the bitmask field recording which fields have been changed by
+// generated setters since the last reset, Fields are the tracked fields in the
+// order of their bits, and Lock is the lock of visc:all guarding the bitmask.
+type trackCtx struct {
+	Field  string
+	Bits   int
+	Fields []string
+	Lock   *lockCtx
+}
+
+// mark returns the statement recording the change of field, an empty string
+// is returned if track is nil. Every method modifying the same field shares a
+// single bit.
+func (track *trackCtx) mark(field string) string {
+	if track == nil {
+		return ""
+	}
+	bit := len(track.Fields)
+	for i, tracked := range track.Fields {
+		if tracked == field {
+			bit = i
+			break
+		}
+	}
+	if bit == len(track.Fields) {
+		track.Fields = append(track.Fields, field)
+	}
+	return fmt.Sprintf("instance.%s |= 1 << %d", track.Field, bit)
+}
+
+// trackField returns the name of the bitmask field of the "visc:track"
+// directive of t, which is "changes" by default.
+func trackField(t *inspect.Type) (name string, ok bool) {
+	directive, found := lookupDirective(docComments(t), "track")
+	if !found {
+		return "", false
+	}
+	if name, found = directive.Lookup("field"); !found || name == "" {
+		name = "changes"
+	}
+	return name, true
+}
+
+// bookkeeping reports whether field is the bitmask of the "visc:track"
+// directive of t, which records changes of t rather than holding its data, so
+// it is left out of the methods generated for the data of t.
+func bookkeeping(t *inspect.Type, field *inspect.Field) bool {
+	name, ok := trackField(t)
+	return ok && !field.Embedded && field.Name == name
+}
+
+// lookupTrack verifies that t has a field called name of an unsigned integer
+// type to hold the bitmask of "visc:track", generation is aborted otherwise.
+func (g *Generator) lookupTrack(t *inspect.Type, name string) *trackCtx {
+	position := g.GetFset().Position(t.Spec.Name.Pos())
+	for _, field := range t.Fields {
+		if field.Name != name {
+			continue
+		}
+		if basic, ok := field.Type.Underlying().(*types.Basic); ok {
+			switch basic.Kind() {
+			case types.Uint8:
+				return &trackCtx{Field: name, Bits: 8}
+			case types.Uint16:
+				return &trackCtx{Field: name, Bits: 16}
+			case types.Uint32, types.Uint, types.Uintptr:
+				return &trackCtx{Field: name, Bits: 32}
+			case types.Uint64:
+				return &trackCtx{Field: name, Bits: 64}
+			}
+		}
+		log.Fatalf("%s: field %s of visc:track should be an unsigned integer, but it is %s",
+			position, name, g.typeString(field.Type))
+	}
+	log.Fatalf("%s: visc:track needs a field to record changes, e.g. `%s uint64`", position, name)
+	return nil
+}
+
+// genTrack generates the methods reporting and resetting the changes recorded
+// by generated setters, which hold the lock of visc:all if there is one.
+func (g *Generator) genTrack(t *inspect.Type, track *trackCtx) {
+	if len(track.Fields) > track.Bits {
+		log.Fatalf("%s: %d fields are tracked, but field %s only has %d bits",
+			g.GetFset().Position(t.Spec.Name.Pos()), len(track.Fields), track.Field, track.Bits)
+	}
+	// bits are assigned in the order fields are generated, while Changed
+	// reports them in the order of declaration
+	declared := make(map[string]int, len(t.Fields))
+	for index, field := range t.Fields {
+		declared[field.Name] = index
+	}
+	bits := make([]int, len(track.Fields))
+	for bit := range bits {
+		bits[bit] = bit
+	}
+	sort.SliceStable(bits, func(i, j int) bool {
+		return declared[track.Fields[bits[i]]] < declared[track.Fields[bits[j]]]
+	})
+	receiver := t.String()
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) Changed() []string {\n", receiver)
+	track.Lock.acquire(&g.out, true)
+	fmt.Fprintf(&g.out, "changed := make([]string, 0, %d)\n", len(track.Fields))
+	for _, bit := range bits {
+		fmt.Fprintf(&g.out, "if instance.%s&(1<<%d) != 0 {\nchanged = append(changed, %q)\n}\n", track.Field, bit, track.Fields[bit])
+	}
+	fmt.Fprintf(&g.out, "return changed\n")
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) IsChanged(field string) bool {\n", receiver)
+	if len(track.Fields) > 0 {
+		track.Lock.acquire(&g.out, true)
+		fmt.Fprintf(&g.out, "switch field {\n")
+		for bit, field := range track.Fields {
+			fmt.Fprintf(&g.out, "case %q:\nreturn instance.%s&(1<<%d) != 0\n", field, track.Field, bit)
+		}
+		fmt.Fprintf(&g.out, "}\n")
+	}
+	fmt.Fprintf(&g.out, "return false\n")
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) ResetChanges() {\n", receiver)
+	track.Lock.acquire(&g.out, false)
+	fmt.Fprintf(&g.out, "instance.%s = 0\n", track.Field)
+	fmt.Fprintf(&g.out, "}\n")
+}
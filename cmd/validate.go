@@ -0,0 +1,294 @@
+package cmd
+
+import (
+	"fmt"
+	"go/types"
+	"io"
+	"log"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// validateRule is a rule of the validate tag in the form of name or
+// name=value, e.g. `validate:"required,min=1,max=64"`.
+type validateRule struct {
+	Name  string
+	Value string
+}
+
+// parseValidateRules splits a validate tag into rules, the value of a regexp
+// rule extends to the end of the tag so that patterns may contain commas.
+func parseValidateRules(tag string) []validateRule {
+	var rules []validateRule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(strings.TrimSpace(tag), "regexp=") {
+			part, tag = tag, ""
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], tag[i+1:]
+		} else {
+			part, tag = tag, ""
+		}
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(part, "=")
+		rules = append(rules, validateRule{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+	return rules
+}
+
+// genValidate generates a Validate method for t if it has the "visc:validate"
+// directive, the supported rules of validate tags are required, min, max, oneof
+// and regexp, and malformed rules are reported at generation time. Validate
+// tags of types without the directive are left to other validators. The
+// fields are read holding the lock of "visc:all(lock=mu)", if any.
+func (g *Generator) genValidate(t *inspect.Type) {
+	if _, ok := lookupDirective(docComments(t), "validate"); !ok {
+		return
+	}
+	type validateCtx struct {
+		Field *inspect.Field
+		Rules []validateRule
+	}
+	cx := make([]*validateCtx, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		tag, ok := structTag(field).Lookup("validate")
+		if !ok || strings.TrimSpace(tag) == "-" {
+			continue
+		}
+		if rules := parseValidateRules(tag); len(rules) > 0 {
+			cx = append(cx, &validateCtx{Field: field, Rules: rules})
+		}
+	}
+	if len(cx) == 0 {
+		return
+	}
+	var (
+		name     = t.Spec.Name.String()
+		receiver = t.String()
+		body     strings.Builder
+		vars     int
+	)
+	for _, ctx := range cx {
+		field := ctx.Field
+		v := &validator{g: g, field: field, name: name}
+		expr, typ := "instance."+field.Name, g.fieldType(field)
+		if value, isAtomic := atomicValue(typ); isAtomic {
+			vars++
+			expr, typ = fmt.Sprintf("v%d", vars), value
+			fmt.Fprintf(&body, "%s := instance.%s.Load()\n", expr, field.Name)
+		} else if containsLock(typ) {
+			v.fatalf("validate tag is not supported on lock field %s", field.Name)
+		}
+		v.validate(&body, expr, typ, ctx.Rules)
+	}
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) Validate() error {\n", receiver)
+	fmt.Fprintf(&g.out, "var invalid []string\n")
+	g.allLock(t).acquire(&g.out, true)
+	fmt.Fprintf(&g.out, "%s", body.String())
+	fmt.Fprintf(&g.out, "if len(invalid) > 0 {\n")
+	fmt.Fprintf(&g.out, "return %s.Errorf(\"%s: invalid fields: %%s\", %s.Join(invalid, \"; \"))\n",
+		g.importPackage("fmt"), name, g.importPackage("strings"))
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "return nil\n")
+	fmt.Fprintf(&g.out, "}")
+}
+
+// validator generates the statements validating a single field, every failed
+// rule appends a message to the invalid slice of the enclosing function.
+type validator struct {
+	g     *Generator
+	field *inspect.Field
+	name  string
+}
+
+func (v *validator) fatalf(format string, args ...any) {
+	log.Fatalf("%s: %s", v.g.GetFset().Position(v.field.Ast.Pos()), fmt.Sprintf(format, args...))
+}
+
+func (v *validator) fail(w io.Writer, message string) {
+	fmt.Fprintf(w, "invalid = append(invalid, %q)\n", v.field.Name+" "+message)
+}
+
+// validate generates the checks of rules on expr, the rules other than
+// required apply to the value pointed to if typ is a pointer.
+func (v *validator) validate(w io.Writer, expr string, typ types.Type, rules []validateRule) {
+	var deref []validateRule
+	for _, rule := range rules {
+		switch rule.Name {
+		case "required":
+			if rule.Value != "" {
+				v.fatalf("malformed validate rule %q: required takes no value", rule.Name+"="+rule.Value)
+			}
+			v.required(w, expr, typ)
+		case "min", "max", "oneof", "regexp":
+			if rule.Value == "" {
+				v.fatalf("malformed validate rule %q: %s requires a value", rule.Name, rule.Name)
+			}
+			deref = append(deref, rule)
+		default:
+			v.fatalf("unknown validate rule %q", rule.Name)
+		}
+	}
+	if len(deref) == 0 {
+		return
+	}
+	if ptr, isPtr := typ.Underlying().(*types.Pointer); isPtr {
+		fmt.Fprintf(w, "if %s != nil {\n", expr)
+		defer fmt.Fprintf(w, "}\n")
+		expr, typ = "(*"+expr+")", ptr.Elem()
+	}
+	for _, rule := range deref {
+		switch rule.Name {
+		case "min":
+			v.bound(w, expr, typ, rule, "<", "at least")
+		case "max":
+			v.bound(w, expr, typ, rule, ">", "at most")
+		case "oneof":
+			v.oneof(w, expr, typ, rule)
+		case "regexp":
+			v.regexp(w, expr, typ, rule)
+		}
+	}
+}
+
+func (v *validator) required(w io.Writer, expr string, typ types.Type) {
+	var zero string
+	switch u := typ.Underlying().(type) {
+	case *types.Interface:
+		if _, isParam := typ.(*types.TypeParam); !isParam {
+			zero = expr + " == nil"
+		}
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			zero = "!" + expr
+		case u.Info()&types.IsString != 0:
+			zero = expr + ` == ""`
+		case u.Info()&types.IsNumeric != 0:
+			zero = expr + " == 0"
+		}
+	case *types.Pointer, *types.Signature, *types.Chan:
+		zero = expr + " == nil"
+	case *types.Slice, *types.Map:
+		zero = "len(" + expr + ") == 0"
+	}
+	if zero == "" {
+		if !types.Comparable(typ) {
+			v.fatalf("malformed validate rule %q: %s is not comparable", "required", v.g.typeString(typ))
+		}
+		zero = expr + " == *new(" + v.g.typeString(typ) + ")"
+	}
+	fmt.Fprintf(w, "if %s {\n", zero)
+	v.fail(w, "is required")
+	fmt.Fprintf(w, "}\n")
+}
+
+// bound generates min and max, which limit numbers by value, and strings,
+// slices, maps, arrays and channels by length.
+func (v *validator) bound(w io.Writer, expr string, typ types.Type, rule validateRule, op string, desc string) {
+	basic, isBasic := typ.Underlying().(*types.Basic)
+	switch {
+	case isBasic && basic.Info()&types.IsNumeric != 0:
+		v.number(basic, rule)
+		fmt.Fprintf(w, "if %s %s %s {\n", expr, op, rule.Value)
+		v.fail(w, "must be "+desc+" "+rule.Value)
+	case isBasic && basic.Info()&types.IsString != 0:
+		v.length(rule)
+		fmt.Fprintf(w, "if %s.RuneCountInString(%s) %s %s {\n", v.g.importPackage("unicode/utf8"), expr, op, rule.Value)
+		v.fail(w, "length must be "+desc+" "+rule.Value)
+	default:
+		switch typ.Underlying().(type) {
+		case *types.Slice, *types.Map, *types.Array, *types.Chan:
+		default:
+			v.fatalf("malformed validate rule %q: %s is not supported on %s",
+				rule.Name+"="+rule.Value, rule.Name, v.g.typeString(typ))
+		}
+		v.length(rule)
+		fmt.Fprintf(w, "if len(%s) %s %s {\n", expr, op, rule.Value)
+		v.fail(w, "length must be "+desc+" "+rule.Value)
+	}
+	fmt.Fprintf(w, "}\n")
+}
+
+// number checks that the value of rule is a constant representable by the
+// numeric type.
+func (v *validator) number(basic *types.Basic, rule validateRule) {
+	var (
+		err  error
+		bits = int(types.SizesFor("gc", "amd64").Sizeof(basic) * 8)
+	)
+	switch {
+	case basic.Info()&types.IsUnsigned != 0:
+		_, err = strconv.ParseUint(rule.Value, 0, bits)
+	case basic.Info()&types.IsInteger != 0:
+		_, err = strconv.ParseInt(rule.Value, 0, bits)
+	case basic.Info()&types.IsFloat != 0:
+		_, err = strconv.ParseFloat(rule.Value, bits)
+	default:
+		err = fmt.Errorf("%s is not supported on %s", rule.Name, basic.Name())
+	}
+	if err != nil {
+		v.fatalf("malformed validate rule %q: %s", rule.Name+"="+rule.Value, err)
+	}
+}
+
+// length checks that the value of rule is a valid length.
+func (v *validator) length(rule validateRule) {
+	if _, err := strconv.ParseUint(rule.Value, 10, 0); err != nil {
+		v.fatalf("malformed validate rule %q: %s", rule.Name+"="+rule.Value, err)
+	}
+}
+
+func (v *validator) oneof(w io.Writer, expr string, typ types.Type, rule validateRule) {
+	basic, isBasic := typ.Underlying().(*types.Basic)
+	if !isBasic || basic.Info()&(types.IsString|types.IsInteger) == 0 {
+		v.fatalf("malformed validate rule %q: oneof is not supported on %s",
+			rule.Name+"="+rule.Value, v.g.typeString(typ))
+	}
+	var (
+		values = strings.Fields(rule.Value)
+		cases  = make([]string, 0, len(values))
+		seen   = make(map[string]string, len(values))
+	)
+	for _, value := range values {
+		key := value
+		if basic.Info()&types.IsString != 0 {
+			cases = append(cases, strconv.Quote(value))
+		} else {
+			v.number(basic, validateRule{Name: rule.Name, Value: value})
+			cases = append(cases, value)
+			// integers are compared by value, e.g. 1 and 0x1 are the same case
+			n, _ := new(big.Int).SetString(value, 0)
+			key = n.String()
+		}
+		if prev, dup := seen[key]; dup {
+			v.fatalf("malformed validate rule %q: %s duplicates %s",
+				rule.Name+"="+rule.Value, value, prev)
+		}
+		seen[key] = value
+	}
+	fmt.Fprintf(w, "switch %s {\ncase %s:\ndefault:\n", expr, strings.Join(cases, ", "))
+	v.fail(w, "must be one of "+strings.Join(values, ", "))
+	fmt.Fprintf(w, "}\n")
+}
+
+func (v *validator) regexp(w io.Writer, expr string, typ types.Type, rule validateRule) {
+	if basic, isBasic := typ.Underlying().(*types.Basic); !isBasic || basic.Info()&types.IsString == 0 {
+		v.fatalf("malformed validate rule %q: regexp is not supported on %s",
+			rule.Name+"="+rule.Value, v.g.typeString(typ))
+	}
+	if _, err := regexp.Compile(rule.Value); err != nil {
+		v.fatalf("malformed validate rule %q: %s", rule.Name+"="+rule.Value, err)
+	}
+	pattern := "validate" + v.name + toCamel(v.field.Name) + "Regexp"
+	fmt.Fprintf(&v.g.out, "\n\nvar %s = %s.MustCompile(%q)", pattern, v.g.importPackage("regexp"), rule.Value)
+	fmt.Fprintf(w, "if !%s.MatchString(%s) {\n", pattern, expr)
+	v.fail(w, "must match "+rule.Value)
+	fmt.Fprintf(w, "}\n")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/x5iu/visc/inspect"
+)
+
+// genVisit generates VisitFields, VisitFieldPointers and FieldTag for t if it
+// has the "visc:visit" directive, fields are visited in declaration order
+// until the visitor returns false. VisitFields reads the fields holding the
+// lock of "visc:all(lock=mu)", if any, but not while calling the visitor.
+func (g *Generator) genVisit(t *inspect.Type) {
+	if _, ok := lookupDirective(docComments(t), "visit"); !ok {
+		return
+	}
+	receiver := t.String()
+	fields := make([]*inspect.Field, 0, len(t.Fields))
+	for _, field := range t.Fields {
+		if field.Name != "_" && !bookkeeping(t, field) {
+			fields = append(fields, field)
+		}
+	}
+	var (
+		names  = make([]string, 0, len(fields))
+		values = make([]string, 0, len(fields))
+		lock   = g.allLock(t)
+	)
+	for _, field := range fields {
+		value := "instance." + field.Name
+		if _, isAtomic := atomicValue(field.Type); isAtomic {
+			value += ".Load()"
+		} else if containsLock(field.Type) {
+			// locks are only visited by VisitFieldPointers
+			continue
+		}
+		names, values = append(names, field.Name), append(values, value)
+	}
+	fmt.Fprintf(&g.out, "\n\nfunc (instance *%s) VisitFields(fn func(name string, value any) bool) {\n", receiver)
+	if lock != nil && len(values) > 0 {
+		// the values are read holding the lock, which is released before fn
+		// is called so that fn may call the accessors of instance
+		fmt.Fprintf(&g.out, "instance.%s.%s()\n", lock.Field, lock.rlock())
+		for i, value := range values {
+			fmt.Fprintf(&g.out, "v%d := %s\n", i+1, value)
+			values[i] = fmt.Sprintf("v%d", i+1)
+		}
+		fmt.Fprintf(&g.out, "instance.%s.%s()\n", lock.Field, lock.runlock())
+	}
+	for i, name := range names {
+		fmt.Fprintf(&g.out, "if !fn(%q, %s) {\nreturn\n}\n", name, values[i])
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) VisitFieldPointers(fn func(name string, ptr any) bool) {\n", receiver)
+	for _, field := range fields {
+		fmt.Fprintf(&g.out, "if !fn(%q, &instance.%s) {\nreturn\n}\n", field.Name, field.Name)
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "\nfunc (instance *%s) FieldTag(name string) %s.StructTag {\n", receiver, g.importPackage("reflect"))
+	fmt.Fprintf(&g.out, "switch name {\n")
+	for _, field := range fields {
+		if field.Ast.Tag != nil {
+			fmt.Fprintf(&g.out, "case %q:\nreturn %s\n", field.Name, field.Ast.Tag.Value)
+		}
+	}
+	fmt.Fprintf(&g.out, "}\n")
+	fmt.Fprintf(&g.out, "return \"\"\n")
+	fmt.Fprintf(&g.out, "}")
+}
@@ -27,6 +27,31 @@ func GetPackagePath(dir string) (string, error) {
 	return getPackagePathFromGoMod(dir, gomod)
 }
 
+// ListPackages returns the directories of packages matched by patterns, which
+// are interpreted the same way as the "go list" command does, e.g. "./...".
+// Directories without any non-test go file are left out since there is
+// nothing to generate code for.
+func ListPackages(patterns []string) ([]string, error) {
+	args := append([]string{"list", "-f", "{{if or .GoFiles .CgoFiles}}{{.Dir}}{{end}}", "--"}, patterns...)
+	command := exec.Command("go", args...)
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	out, err := command.Output()
+	if err != nil {
+		if msg := bytes.TrimSpace(stderr.Bytes()); len(msg) > 0 {
+			return nil, fmt.Errorf("go list: %s", msg)
+		}
+		return nil, err
+	}
+	dirs := make([]string, 0, 8)
+	for _, line := range strings.Split(string(out), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			dirs = append(dirs, line)
+		}
+	}
+	return dirs, nil
+}
+
 func getGoModPath(dir string) (string, error) {
 	command := exec.Command("go", "env", "GOMOD")
 	command.Dir = dir
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/build"
+	"go/importer"
 	"go/parser"
 	"go/printer"
 	"go/token"
@@ -13,12 +14,15 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
 type Package struct {
-	fset *token.FileSet
-	info *types.Info
+	fset   *token.FileSet
+	info   *types.Info
+	types  *types.Package
+	errors []types.Error
 
 	Name    string
 	Imports []*Import
@@ -33,15 +37,105 @@ func (p *Package) GetInfo() *types.Info {
 	return p.info
 }
 
+func (p *Package) GetTypes() *types.Package {
+	return p.types
+}
+
+// GetErrors returns the type errors found in the scanned files, in the order
+// they are reported.
+func (p *Package) GetErrors() []types.Error {
+	return p.errors
+}
+
+// Import is an import spec found in one of the scanned files, Name is empty
+// unless the spec names the package explicitly, which includes "." and "_".
 type Import struct {
 	Name string
 	Path string
 }
 
 type Type struct {
-	Fset *token.FileSet
-	Decl *ast.GenDecl
-	Spec *ast.TypeSpec
+	Fset   *token.FileSet
+	Decl   *ast.GenDecl
+	Spec   *ast.TypeSpec
+	Named  *types.Named
+	Fields []*Field
+}
+
+// Field is a single struct field of a target Type, fields declared together
+// (e.g. `a, b int`) are split into one Field per name and share the same Ast.
+type Field struct {
+	Name     string
+	Embedded bool
+	Ast      *ast.Field
+	Type     types.Type
+}
+
+func namedOf(info *types.Info, spec *ast.TypeSpec) *types.Named {
+	if obj, ok := info.Defs[spec.Name].(*types.TypeName); ok {
+		if named, ok := obj.Type().(*types.Named); ok {
+			return named
+		}
+	}
+	return nil
+}
+
+func fieldsOf(info *types.Info, spec *ast.TypeSpec) []*Field {
+	structType := spec.Type.(*ast.StructType)
+	fields := make([]*Field, 0, len(structType.Fields.List))
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			fields = append(fields, &Field{
+				Name:     embeddedName(field.Type),
+				Embedded: true,
+				Ast:      field,
+				Type:     typeOf(info, field.Type),
+			})
+			continue
+		}
+		for _, name := range field.Names {
+			var typ types.Type
+			if obj := info.Defs[name]; obj != nil {
+				typ = obj.Type()
+			} else {
+				typ = typeOf(info, field.Type)
+			}
+			fields = append(fields, &Field{
+				Name: name.String(),
+				Ast:  field,
+				Type: typ,
+			})
+		}
+	}
+	return fields
+}
+
+func typeOf(info *types.Info, expr ast.Expr) types.Type {
+	if typ := info.TypeOf(expr); typ != nil {
+		return typ
+	}
+	return types.Typ[types.Invalid]
+}
+
+// embeddedName returns the implicit field name of an embedded field, which is
+// the unqualified type name with pointer and type arguments stripped.
+func embeddedName(expr ast.Expr) string {
+	for {
+		switch x := expr.(type) {
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.IndexListExpr:
+			expr = x.X
+		case *ast.SelectorExpr:
+			return x.Sel.String()
+		case *ast.Ident:
+			return x.String()
+		default:
+			return ""
+		}
+	}
 }
 
 func (t *Type) String() string {
@@ -61,7 +155,7 @@ func (t *Type) String() string {
 	return spec
 }
 
-func Scan(dir string, files []string, types []string) (*Package, error) {
+func Scan(dir string, files []string, typeNames []string) (*Package, error) {
 	if !filepath.IsAbs(dir) {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -74,13 +168,13 @@ func Scan(dir string, files []string, types []string) (*Package, error) {
 			return nil, fmt.Errorf("file %q is not in directory %q", file, dir)
 		}
 	}
-	fset := token.NewFileSet()
-	packages, err := parser.ParseDir(fset, dir, filter(files), parser.ParseComments)
+	fset := sourceImporter.tokenFileSet
+	packages, err := parser.ParseDir(fset, dir, filter(dir, files), parser.ParseComments)
 	if err != nil {
 		return nil, err
 	}
 	if l := len(packages); l != 1 {
-		return nil, fmt.Errorf("%d packages found", l)
+		return nil, fmt.Errorf("%d packages found in directory %q", l, dir)
 	}
 	out := &Package{
 		fset:    fset,
@@ -92,77 +186,73 @@ func Scan(dir string, files []string, types []string) (*Package, error) {
 		for _, file := range p.Files {
 			astFiles = append(astFiles, file)
 		}
-		//conf := types.Config{
-		//	IgnoreFuncBodies: true,
-		//	FakeImportC:      true,
-		//	Importer: &Importer{
-		//		imported:      map[string]*types.Package{},
-		//		tokenFileSet:  fset,
-		//		defaultImport: importer.Default(),
-		//	},
-		//}
-		//info := &types.Info{
-		//	Types:      map[ast.Expr]types.TypeAndValue{},
-		//	Instances:  map[*ast.Ident]types.Instance{},
-		//	Defs:       map[*ast.Ident]types.Object{},
-		//	Uses:       map[*ast.Ident]types.Object{},
-		//	Implicits:  map[ast.Node]types.Object{},
-		//	Selections: map[*ast.SelectorExpr]*types.Selection{},
-		//	Scopes:     map[ast.Node]*types.Scope{},
-		//	InitOrder:  []*types.Initializer{},
-		//}
-		//_, err = conf.Check(p.Name, fset, astFiles, info)
-		//if err != nil {
-		//	return nil, err
-		//}
-		//out.info = info
-		//imports := make(map[string]*ast.Ident, 8)
-		//imported := make(map[string]struct{})
+		conf := types.Config{
+			IgnoreFuncBodies: true,
+			FakeImportC:      true,
+			Importer:         sourceImporter,
+			// The package being scanned usually contains the previously generated
+			// file, which may refer to fields or methods that no longer exist; those
+			// errors are tolerated so that the rest of the package is still resolved,
+			// and kept to explain the types that can not be resolved.
+			Error: func(err error) {
+				if typeErr, ok := err.(types.Error); ok {
+					out.errors = append(out.errors, typeErr)
+				}
+			},
+		}
+		info := &types.Info{
+			Types:      map[ast.Expr]types.TypeAndValue{},
+			Instances:  map[*ast.Ident]types.Instance{},
+			Defs:       map[*ast.Ident]types.Object{},
+			Uses:       map[*ast.Ident]types.Object{},
+			Implicits:  map[ast.Node]types.Object{},
+			Selections: map[*ast.SelectorExpr]*types.Selection{},
+			Scopes:     map[ast.Node]*types.Scope{},
+			InitOrder:  []*types.Initializer{},
+		}
+		pkgPath, err := GetPackagePath(dir)
+		if err != nil {
+			pkgPath = p.Name
+		}
+		typesPkg, _ := conf.Check(pkgPath, fset, astFiles, info)
+		out.types = typesPkg
+		out.info = info
+		imported := make(map[Import]struct{})
 		ast.Inspect(p, func(input ast.Node) bool {
 			switch node := input.(type) {
 			case *ast.Package:
 				out.Name = node.Name
 				return true
-			//case *ast.ImportSpec:
-			//	path, _ := strconv.Unquote(node.Path.Value)
-			//	imports[path] = node.Name
-			//	return true
 			case *ast.File:
+				for _, spec := range node.Imports {
+					path, err := strconv.Unquote(spec.Path.Value)
+					if err != nil {
+						continue
+					}
+					var name string
+					if spec.Name != nil {
+						name = spec.Name.String()
+					}
+					imp := Import{Name: name, Path: path}
+					if _, duplicated := imported[imp]; !duplicated {
+						out.Imports = append(out.Imports, &imp)
+						imported[imp] = struct{}{}
+					}
+				}
 				return true
 			case *ast.GenDecl:
 				if node.Tok == token.TYPE {
 					for _, spec := range node.Specs {
 						if typeSpec, ok := spec.(*ast.TypeSpec); ok {
 							if _, ok := typeSpec.Type.(*ast.StructType); ok {
-								if filterTypes(types, typeSpec.Name.String()) {
+								if filterTypes(typeNames, typeSpec.Name.String()) {
 									out.Targets = append(out.Targets, &Type{
-										Fset: fset,
-										Decl: node,
-										Spec: typeSpec,
+										Fset:   fset,
+										Decl:   node,
+										Spec:   typeSpec,
+										Named:  namedOf(info, typeSpec),
+										Fields: fieldsOf(info, typeSpec),
 									})
-									//ast.Inspect(typeSpec.Type, func(structType ast.Node) bool {
-									//	switch expr := structType.(type) {
-									//	case ast.Expr:
-									//		if named, ok := info.TypeOf(expr).(*types.Named); ok {
-									//			if objPkg := named.Obj().Pkg(); objPkg != nil {
-									//				if alias, exists := imports[objPkg.Path()]; exists {
-									//					if _, duplicated := imported[objPkg.Path()]; !duplicated {
-									//						var name string
-									//						if alias != nil {
-									//							name = alias.String()
-									//						}
-									//						out.Imports = append(out.Imports, &Import{
-									//							Name: name,
-									//							Path: objPkg.Path(),
-									//						})
-									//						imported[objPkg.Path()] = struct{}{}
-									//					}
-									//				}
-									//			}
-									//		}
-									//	}
-									//	return true
-									//})
 								}
 							}
 						}
@@ -188,10 +278,17 @@ func filterTypes(types []string, defType string) bool {
 	return false
 }
 
-func filter(files []string) func(info fs.FileInfo) bool {
+func filter(dir string, files []string) func(info fs.FileInfo) bool {
 	return func(info fs.FileInfo) bool {
 		if len(files) == 0 {
-			return true
+			// test files and files excluded by build constraints (e.g. a
+			// "//go:build ignore" program in package main) belong to other
+			// packages, only files of the package itself are scanned
+			if strings.HasSuffix(info.Name(), "_test.go") {
+				return false
+			}
+			match, err := build.Default.MatchFile(dir, info.Name())
+			return err != nil || match
 		}
 		for _, file := range files {
 			if info.Name() == filepath.Base(file) {
@@ -202,8 +299,26 @@ func filter(files []string) func(info fs.FileInfo) bool {
 	}
 }
 
+// defaultImporter is shared by all scans so that packages imported from export
+// data are only loaded once when scanning several packages.
+var defaultImporter = importer.Default()
+
+// sourceImporter is shared by all scans so that packages imported from source
+// are only parsed and checked once when scanning several packages, the scanned
+// files are added to its FileSet too, so that positions of imported objects
+// are resolved by the FileSet of every scanned Package. Packages are scanned
+// within one module (or workspace), where an import path always refers to the
+// same package.
+var sourceImporter = &Importer{
+	imported:      map[string]*types.Package{},
+	docs:          map[*types.TypeName][]*ast.Comment{},
+	tokenFileSet:  token.NewFileSet(),
+	defaultImport: defaultImporter,
+}
+
 type Importer struct {
 	imported      map[string]*types.Package
+	docs          map[*types.TypeName][]*ast.Comment // doc comments of types imported from source
 	tokenFileSet  *token.FileSet
 	defaultImport types.Importer
 }
@@ -228,26 +343,10 @@ func (importer *Importer) ImportFrom(path, dir string, _ types.ImportMode) (*typ
 				return target, nil
 			}
 			importer.imported[path] = &importing
-			pkg, err := build.Import(path, dir, 0)
-			if err != nil {
-				return nil, err
-			}
-			var files []*ast.File
-			for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
-				name = filepath.Join(pkg.Dir, name)
-				file, err := parser.ParseFile(importer.tokenFileSet, name, nil, 0)
-				if err != nil {
-					return nil, err
-				}
-				files = append(files, file)
-			}
-			conf := types.Config{
-				Importer:         importer,
-				FakeImportC:      true,
-				IgnoreFuncBodies: true,
-			}
-			target, err = conf.Check(path, importer.tokenFileSet, files, nil)
+			target, err := importer.check(path, dir)
 			if err != nil {
+				// the import may succeed from another directory
+				delete(importer.imported, path)
 				return nil, err
 			}
 			importer.imported[path] = target
@@ -257,6 +356,65 @@ func (importer *Importer) ImportFrom(path, dir string, _ types.ImportMode) (*typ
 	return importer.defaultImport.Import(path)
 }
 
+// check parses and checks the package imported as path from dir.
+func (importer *Importer) check(path, dir string) (*types.Package, error) {
+	pkg, err := build.Import(path, dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	var files []*ast.File
+	for _, name := range append(pkg.GoFiles, pkg.CgoFiles...) {
+		name = filepath.Join(pkg.Dir, name)
+		file, err := parser.ParseFile(importer.tokenFileSet, name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{
+		Importer:         importer,
+		FakeImportC:      true,
+		IgnoreFuncBodies: true,
+	}
+	info := &types.Info{Defs: map[*ast.Ident]types.Object{}}
+	checked, err := conf.Check(path, importer.tokenFileSet, files, info)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				obj, ok := info.Defs[typeSpec.Name].(*types.TypeName)
+				if !ok {
+					continue
+				}
+				var docs []*ast.Comment
+				if genDecl.Doc != nil {
+					docs = append(docs, genDecl.Doc.List...)
+				}
+				if typeSpec.Doc != nil {
+					docs = append(docs, typeSpec.Doc.List...)
+				}
+				importer.docs[obj] = docs
+			}
+		}
+	}
+	return checked, nil
+}
+
+// TypeDoc returns the doc comments of the declaration of obj, both of the
+// declaration group and of the type spec, if obj is declared in a package
+// imported from source, which tell the directives of types in other packages
+// whose generated methods may not exist yet.
+func TypeDoc(obj *types.TypeName) []*ast.Comment {
+	return sourceImporter.docs[obj]
+}
+
 func (importer *Importer) Import(path string) (*types.Package, error) {
 	return importer.ImportFrom(path, "", 0)
 }